@@ -0,0 +1,9 @@
+-- Drop indexes first
+DROP INDEX IF EXISTS idx_workflow_histories_user_id;
+DROP INDEX IF EXISTS idx_workflow_histories_repo;
+DROP INDEX IF EXISTS idx_workflow_histories_status;
+DROP INDEX IF EXISTS idx_workflow_histories_created_at;
+DROP INDEX IF EXISTS idx_workflow_histories_deleted_at;
+
+-- Drop workflow histories table
+DROP TABLE IF EXISTS workflow_histories;
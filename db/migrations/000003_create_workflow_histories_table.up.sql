@@ -0,0 +1,38 @@
+-- Create workflow histories table recording every workflow create/update attempt
+CREATE TABLE IF NOT EXISTS workflow_histories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL DEFAULT 'create',
+    owner TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    workflow_name TEXT NOT NULL,
+    deployment_type VARCHAR(20) NOT NULL,
+    file_path TEXT,
+    content_sha TEXT,
+    request_payload JSONB,
+    yaml_content TEXT,
+    pull_request_url TEXT,
+    pull_request_number BIGINT,
+    status VARCHAR(20) NOT NULL,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+
+    -- Foreign key constraint
+    CONSTRAINT fk_workflow_histories_user FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_workflow_histories_user_id ON workflow_histories(user_id);
+CREATE INDEX IF NOT EXISTS idx_workflow_histories_repo ON workflow_histories(owner, repository);
+CREATE INDEX IF NOT EXISTS idx_workflow_histories_status ON workflow_histories(status);
+CREATE INDEX IF NOT EXISTS idx_workflow_histories_created_at ON workflow_histories(created_at);
+CREATE INDEX IF NOT EXISTS idx_workflow_histories_deleted_at ON workflow_histories(deleted_at);
+
+-- Add comment
+COMMENT ON TABLE workflow_histories IS 'Records workflow create and update attempts with their request, generated YAML and outcome';
+COMMENT ON COLUMN workflow_histories.request_payload IS 'Request body with dotenv credentials removed';
@@ -0,0 +1,9 @@
+-- Drop indexes first
+DROP INDEX IF EXISTS idx_workflow_pull_requests_number;
+DROP INDEX IF EXISTS idx_workflow_pull_requests_user_id;
+DROP INDEX IF EXISTS idx_workflow_pull_requests_head_sha;
+DROP INDEX IF EXISTS idx_workflow_pull_requests_state;
+DROP INDEX IF EXISTS idx_workflow_pull_requests_created_at;
+
+-- Drop workflow pull requests table
+DROP TABLE IF EXISTS workflow_pull_requests;
@@ -0,0 +1,41 @@
+-- Create workflow pull requests table tracking pull requests opened for workflow changes
+CREATE TABLE IF NOT EXISTS workflow_pull_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    owner TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    number BIGINT NOT NULL,
+    workflow_name TEXT NOT NULL,
+    file_path TEXT,
+    url TEXT,
+    title TEXT,
+    head_branch TEXT,
+    base_branch TEXT,
+    head_sha TEXT,
+    state VARCHAR(20) NOT NULL,
+    draft BOOLEAN NOT NULL DEFAULT FALSE,
+    checks_status VARCHAR(20) NOT NULL,
+    review_state VARCHAR(20) NOT NULL,
+    merged_at TIMESTAMP WITH TIME ZONE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    refreshed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    -- Foreign key constraint
+    CONSTRAINT fk_workflow_pull_requests_user FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+-- Create indexes
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflow_pull_requests_number ON workflow_pull_requests(owner, repository, number);
+CREATE INDEX IF NOT EXISTS idx_workflow_pull_requests_user_id ON workflow_pull_requests(user_id);
+CREATE INDEX IF NOT EXISTS idx_workflow_pull_requests_head_sha ON workflow_pull_requests(head_sha);
+CREATE INDEX IF NOT EXISTS idx_workflow_pull_requests_state ON workflow_pull_requests(state);
+CREATE INDEX IF NOT EXISTS idx_workflow_pull_requests_created_at ON workflow_pull_requests(created_at);
+
+-- Add comment
+COMMENT ON TABLE workflow_pull_requests IS 'Pull requests opened for workflow changes with their state as of the last refresh from GitHub';
@@ -0,0 +1,5 @@
+-- Drop indexes first
+DROP INDEX IF EXISTS idx_github_response_cache_updated_at;
+
+-- Drop GitHub response cache table
+DROP TABLE IF EXISTS github_response_cache;
@@ -0,0 +1,18 @@
+-- Create GitHub response cache table keeping responses to revalidate with conditional requests
+CREATE TABLE IF NOT EXISTS github_response_cache (
+    key VARCHAR(64) PRIMARY KEY,
+    etag TEXT,
+    last_modified TEXT,
+    status_code BIGINT,
+    link TEXT,
+    content_type TEXT,
+    body BYTEA,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_github_response_cache_updated_at ON github_response_cache(updated_at);
+
+-- Add comment
+COMMENT ON TABLE github_response_cache IS 'GitHub GET responses with their ETag or Last-Modified validators, per token and URL';
+COMMENT ON COLUMN github_response_cache.key IS 'SHA-256 of the access token and request URL';
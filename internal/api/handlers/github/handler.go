@@ -0,0 +1,40 @@
+package github
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	database "github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/database/repositories"
+	githubClient "github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+)
+
+// Handler handles requests about the caller's GitHub API usage
+type Handler struct {
+	client          *githubClient.Client
+	tokenRepository *database.TokenRepository
+}
+
+// NewHandler creates a new GitHub handler
+func NewHandler(tokenRepo *database.TokenRepository) *Handler {
+	return &Handler{
+		client:          githubClient.NewClient(),
+		tokenRepository: tokenRepo,
+	}
+}
+
+// getAccessToken retrieves access token for user
+func (h *Handler) getAccessToken(userID string) (string, error) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := h.tokenRepository.FindByUserID(userUUID)
+	if err != nil {
+		return "", err
+	}
+	if token == nil {
+		return "", errors.New("access token not found")
+	}
+	return token.AccessToken, nil
+}
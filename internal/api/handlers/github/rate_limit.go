@@ -0,0 +1,62 @@
+package github
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// GetRateLimit returns the caller's GitHub rate limit budget as recorded from
+// recent API responses. It is fetched from GitHub when nothing was recorded
+// yet or with refresh=true; fetching it does not count against the limit.
+// GET /api/github/rate-limit?refresh=
+func (h *Handler) GetRateLimit(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		pkghttp.UnauthorizedResponse(c, "User not found in context")
+		return
+	}
+
+	refresh := false
+	if value := c.Query("refresh"); value != "" {
+		var err error
+		if refresh, err = strconv.ParseBool(value); err != nil {
+			pkghttp.BadRequestResponse(c, "refresh must be true or false")
+			return
+		}
+	}
+
+	accessToken, err := h.getAccessToken(userID.(string))
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
+		return
+	}
+
+	status := h.client.RateLimitStatus(accessToken)
+	if refresh || len(status.Resources) == 0 {
+		status, err = h.client.GetRateLimit(c.Request.Context(), accessToken)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to fetch GitHub rate limit")
+			pkghttp.InternalServerErrorResponse(c, "Failed to fetch rate limit", err)
+			return
+		}
+	}
+
+	policy := h.client.RateLimitPolicy()
+	mode := "fail"
+	if policy.Wait {
+		mode = "wait"
+	}
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Rate limit retrieved successfully", gin.H{
+		"resources":     status.Resources,
+		"blocked_until": status.BlockedUntil,
+		"policy": gin.H{
+			"mode":             mode,
+			"max_wait_seconds": int(policy.MaxWait.Seconds()),
+		},
+	})
+}
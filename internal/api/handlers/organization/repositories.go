@@ -1,15 +1,18 @@
 package organization
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
 	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
 )
 
-// GetRepositories returns all repositories for a specific organization
-// GET /api/organizations/:org/repositories
+// GetRepositories returns the repositories of an organization: all of them, or
+// a single page when page, per_page or cursor is given
+// GET /api/organizations/:org/repositories?page=&per_page=&cursor=
 func (h *Handler) GetRepositories(c *gin.Context) {
 	orgName := c.Param("org")
 	if orgName == "" {
@@ -29,12 +32,41 @@ func (h *Handler) GetRepositories(c *gin.Context) {
 		return
 	}
 
+	params, err := pkghttp.ParsePageParams(c)
+	if err != nil {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return
+	}
+
 	accessToken, err := h.getAccessToken(userUUID)
 	if err != nil {
 		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
 		return
 	}
 
+	if params.Set {
+		page, err := h.organizationService.GetOrganizationRepositoriesPage(c.Request.Context(), accessToken, orgName, github.PageRequest{
+			Page:    params.Page,
+			PerPage: params.PerPage,
+			Cursor:  params.Cursor,
+		})
+		if errors.Is(err, github.ErrInvalidCursor) {
+			pkghttp.BadRequestResponse(c, err.Error())
+			return
+		}
+		if err != nil {
+			pkghttp.InternalServerErrorResponse(c, "Failed to fetch repositories", err)
+			return
+		}
+
+		pkghttp.PaginatedResponse(c, http.StatusOK, "Repositories fetched successfully", gin.H{
+			"organization":     orgName,
+			"repositories":     page.Items,
+			"repository_count": len(page.Items),
+		}, params.Pagination(page.NextCursor, page.Total))
+		return
+	}
+
 	repositories, err := h.organizationService.GetOrganizationRepositories(c.Request.Context(), accessToken, orgName)
 	if err != nil {
 		pkghttp.InternalServerErrorResponse(c, "Failed to fetch repositories", err)
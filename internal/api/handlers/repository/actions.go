@@ -1,16 +1,18 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
 	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
 )
 
-// GetWorkflowRuns returns workflow runs for a specific repository
-// GET /api/repositories/:owner/:repo/actions/runs
+// GetWorkflowRuns returns a page of the workflow runs of a repository, newest first
+// GET /api/repositories/:owner/:repo/actions/runs?page=&per_page=&cursor=
 func (h *Handler) GetWorkflowRuns(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -32,14 +34,10 @@ func (h *Handler) GetWorkflowRuns(c *gin.Context) {
 		return
 	}
 
-	perPage := 30
-	if perPageStr := c.Query("per_page"); perPageStr != "" {
-		var parsed int
-		if _, err := fmt.Sscanf(perPageStr, "%d", &parsed); err == nil {
-			if parsed > 0 && parsed <= 100 {
-				perPage = parsed
-			}
-		}
+	params, err := pkghttp.ParsePageParams(c)
+	if err != nil {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return
 	}
 
 	accessToken, err := h.getAccessToken(userUUID)
@@ -48,18 +46,26 @@ func (h *Handler) GetWorkflowRuns(c *gin.Context) {
 		return
 	}
 
-	runs, err := h.repositoryService.GetWorkflowRuns(c.Request.Context(), accessToken, owner, repo, perPage)
+	page, err := h.repositoryService.GetWorkflowRuns(c.Request.Context(), accessToken, owner, repo, github.PageRequest{
+		Page:    params.Page,
+		PerPage: params.PerPage,
+		Cursor:  params.Cursor,
+	})
+	if errors.Is(err, github.ErrInvalidCursor) {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		pkghttp.InternalServerErrorResponse(c, "Failed to fetch workflow runs", err)
 		return
 	}
 
-	pkghttp.SuccessResponse(c, http.StatusOK, "Workflow runs fetched successfully", gin.H{
+	pkghttp.PaginatedResponse(c, http.StatusOK, "Workflow runs fetched successfully", gin.H{
 		"owner":      owner,
 		"repository": repo,
-		"runs":       runs,
-		"run_count":  len(runs),
-	})
+		"runs":       page.Items,
+		"run_count":  len(page.Items),
+	}, params.Pagination(page.NextCursor, page.Total))
 }
 
 // GetWorkflowRunDetail returns detailed information about a specific workflow run
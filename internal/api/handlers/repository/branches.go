@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
 	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
 )
 
-// GetBranches returns all branches for a specific repository
-// GET /api/repositories/:owner/:repo/branches
+// GetBranches returns the branches of a repository: all of them, or a single
+// page when page, per_page or cursor is given
+// GET /api/repositories/:owner/:repo/branches?page=&per_page=&cursor=
 func (h *Handler) GetBranches(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -31,12 +34,42 @@ func (h *Handler) GetBranches(c *gin.Context) {
 		return
 	}
 
+	params, err := pkghttp.ParsePageParams(c)
+	if err != nil {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return
+	}
+
 	accessToken, err := h.getAccessToken(userUUID)
 	if err != nil {
 		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
 		return
 	}
 
+	if params.Set {
+		page, err := h.repositoryService.GetBranchesPage(c.Request.Context(), accessToken, owner, repo, github.PageRequest{
+			Page:    params.Page,
+			PerPage: params.PerPage,
+			Cursor:  params.Cursor,
+		})
+		if errors.Is(err, github.ErrInvalidCursor) {
+			pkghttp.BadRequestResponse(c, err.Error())
+			return
+		}
+		if err != nil {
+			pkghttp.InternalServerErrorResponse(c, "Failed to fetch branches", err)
+			return
+		}
+
+		pkghttp.PaginatedResponse(c, http.StatusOK, "Branches fetched successfully", gin.H{
+			"owner":        owner,
+			"repository":   repo,
+			"branches":     page.Items,
+			"branch_count": len(page.Items),
+		}, params.Pagination(page.NextCursor, page.Total))
+		return
+	}
+
 	branches, err := h.repositoryService.GetBranches(c.Request.Context(), accessToken, owner, repo)
 	if err != nil {
 		pkghttp.InternalServerErrorResponse(c, "Failed to fetch branches", err)
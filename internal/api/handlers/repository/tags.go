@@ -1,16 +1,19 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
 	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
 )
 
-// GetTags returns all tags for a specific repository
-// GET /api/repositories/:owner/:repo/tags
+// GetTags returns the tags of a repository: all of them, or a single page when
+// page, per_page or cursor is given
+// GET /api/repositories/:owner/:repo/tags?page=&per_page=&cursor=
 func (h *Handler) GetTags(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -32,12 +35,42 @@ func (h *Handler) GetTags(c *gin.Context) {
 		return
 	}
 
+	params, err := pkghttp.ParsePageParams(c)
+	if err != nil {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return
+	}
+
 	accessToken, err := h.getAccessToken(userUUID)
 	if err != nil {
 		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
 		return
 	}
 
+	if params.Set {
+		page, err := h.repositoryService.GetTagsPage(c.Request.Context(), accessToken, owner, repo, github.PageRequest{
+			Page:    params.Page,
+			PerPage: params.PerPage,
+			Cursor:  params.Cursor,
+		})
+		if errors.Is(err, github.ErrInvalidCursor) {
+			pkghttp.BadRequestResponse(c, err.Error())
+			return
+		}
+		if err != nil {
+			pkghttp.InternalServerErrorResponse(c, "Failed to fetch repository tags", err)
+			return
+		}
+
+		pkghttp.PaginatedResponse(c, http.StatusOK, fmt.Sprintf("Successfully retrieved %d tags", len(page.Items)), gin.H{
+			"owner":      owner,
+			"repository": repo,
+			"tags":       page.Items,
+			"count":      len(page.Items),
+		}, params.Pagination(page.NextCursor, page.Total))
+		return
+	}
+
 	tags, err := h.repositoryService.GetTags(c.Request.Context(), accessToken, owner, repo)
 	if err != nil {
 		pkghttp.InternalServerErrorResponse(c, "Failed to fetch repository tags", err)
@@ -0,0 +1,208 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainWorkflow "github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// GetConfig returns the request a workflow was generated from, to reopen it for editing
+// GET /api/workflows/:owner/:repo/config?name=deploy
+func (h *Handler) GetConfig(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		pkghttp.UnauthorizedResponse(c, "User not found in context")
+		return
+	}
+
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+	workflowName := c.Query("name")
+	if workflowName == "" {
+		pkghttp.BadRequestResponse(c, "Workflow name is required")
+		return
+	}
+
+	accessToken, err := h.getAccessToken(userID.(string))
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
+		return
+	}
+
+	config, ok := h.loadConfig(c, userID.(string), accessToken, owner, repo, workflowName)
+	if !ok {
+		return
+	}
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Workflow config retrieved successfully", config)
+}
+
+// Regenerate applies changes to the stored request of a workflow and opens a
+// pull request with the regenerated YAML
+// POST /api/workflows/:owner/:repo/regenerate
+func (h *Handler) Regenerate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		pkghttp.UnauthorizedResponse(c, "User not found in context")
+		return
+	}
+
+	var body domainWorkflow.RegenerateRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		pkghttp.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	accessToken, err := h.getAccessToken(userID.(string))
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
+		return
+	}
+
+	config, ok := h.loadConfig(c, userID.(string), accessToken, owner, repo, body.WorkflowName)
+	if !ok {
+		return
+	}
+
+	request, err := domainWorkflow.ApplyConfigChanges(config.Request, body.Changes)
+	if err != nil {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	history := newHistory(userID.(string), domainWorkflow.HistoryActionRegenerate, owner, repo, request.Redacted())
+	history.WorkflowName = request.WorkflowName
+	history.DeploymentType = request.DeploymentType
+
+	// The file must be on the default branch before anything is generated or stored
+	if err := config.CheckFile(); err != nil {
+		h.recordHistory(history, nil, err)
+		respondWorkflowFileNotFound(c, err)
+		return
+	}
+
+	if err := h.workflowService.ValidateRequest(request); err != nil {
+		h.recordHistory(history, nil, err)
+		pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
+		return
+	}
+
+	logger.Info().
+		Str("owner", owner).
+		Str("repo", repo).
+		Str("workflow_name", request.WorkflowName).
+		Str("config_source", config.Source).
+		Msg("Regenerating workflow")
+
+	// Reject direct commits before a branch or pull request is created
+	if err := h.workflowService.CheckDirectCommit(c.Request.Context(), accessToken, owner, repo, request.DirectCommit); err != nil {
+		h.recordHistory(history, nil, err)
+		if !respondChangeRejected(c, err) {
+			pkghttp.InternalServerErrorResponse(c, "Failed to check direct commit", err)
+		}
+		return
+	}
+
+	yamlContent, ok := h.buildWorkflow(c, accessToken, request, history)
+	if !ok {
+		return
+	}
+
+	response, err := h.workflowService.RegenerateWorkflow(c.Request.Context(), accessToken, config, request, yamlContent, body.CommitMessage)
+	if err != nil {
+		h.recordHistory(history, response, err)
+	}
+	if errors.Is(err, domainWorkflow.ErrWorkflowFileNotFound) {
+		respondWorkflowFileNotFound(c, err)
+		return
+	}
+	if respondChangeRejected(c, err) {
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).
+			Str("owner", owner).
+			Str("repo", repo).
+			Str("workflow_name", request.WorkflowName).
+			Msg("Failed to regenerate workflow")
+		pkghttp.InternalServerErrorResponse(c, "Failed to regenerate workflow", err)
+		return
+	}
+
+	secrets, ok := h.storeDotEnvSecrets(c, accessToken, request, history, response)
+	if !ok {
+		return
+	}
+	response.Secrets = secrets
+	h.recordHistory(history, response, nil)
+
+	pkghttp.SuccessResponse(c, http.StatusOK, response.Message, response)
+}
+
+// respondWorkflowFileNotFound writes the response for regenerating a workflow
+// whose file is not on the default branch
+func respondWorkflowFileNotFound(c *gin.Context, err error) {
+	pkghttp.ErrorResponse(c, http.StatusConflict, "Workflow file is not on the default branch yet; merge its pull request first", err)
+}
+
+// loadConfig reads the stored request of a workflow from its file header,
+// falling back to the caller's latest recorded create or regenerate request;
+// admins fall back to anyone's. Failures are written to the response, in which
+// case ok is false.
+func (h *Handler) loadConfig(c *gin.Context, callerID, accessToken, owner, repo, workflowName string) (*domainWorkflow.WorkflowConfig, bool) {
+	config, err := h.workflowService.GetWorkflowConfig(c.Request.Context(), accessToken, owner, repo, workflowName)
+	if errors.Is(err, domainWorkflow.ErrInvalidWorkflowName) || errors.Is(err, domainWorkflow.ErrInvalidWorkflowConfig) {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return nil, false
+	}
+	if errors.Is(err, domainWorkflow.ErrRepositoryNotFound) {
+		pkghttp.NotFoundResponse(c, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("owner", owner).Str("repo", repo).Str("workflow_name", workflowName).Msg("Failed to read workflow config")
+		pkghttp.InternalServerErrorResponse(c, "Failed to read workflow config", err)
+		return nil, false
+	}
+	if config.Request != nil {
+		return config, true
+	}
+
+	caller, err := uuid.Parse(callerID)
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Invalid user in context")
+		return nil, false
+	}
+	userID, ok := h.scopeToCaller(c, caller, "")
+	if !ok {
+		return nil, false
+	}
+	history, err := h.historyRepository.FindLatestRequest(owner, repo, workflowName, userID)
+	if err != nil {
+		logger.Error().Err(err).Str("owner", owner).Str("repo", repo).Str("workflow_name", workflowName).Msg("Failed to read workflow history")
+		pkghttp.InternalServerErrorResponse(c, "Failed to read workflow config", err)
+		return nil, false
+	}
+	if history == nil {
+		pkghttp.NotFoundResponse(c, domainWorkflow.ErrWorkflowConfigNotFound.Error())
+		return nil, false
+	}
+
+	var request domainWorkflow.Request
+	if err := json.Unmarshal(history.RequestPayload, &request); err != nil {
+		pkghttp.InternalServerErrorResponse(c, "Failed to read workflow config", err)
+		return nil, false
+	}
+	config.Source = domainWorkflow.ConfigSourceHistory
+	config.Request = &request
+	return config, true
+}
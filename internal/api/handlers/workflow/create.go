@@ -1,6 +1,8 @@
 package workflow
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -27,9 +29,14 @@ func (h *Handler) Create(c *gin.Context) {
 		return
 	}
 
+	history := newHistory(userID.(string), domainWorkflow.HistoryActionCreate, request.Owner, request.Repository, request.Redacted())
+	history.WorkflowName = request.WorkflowName
+	history.DeploymentType = request.DeploymentType
+
 	// Validate the request
-	if err := request.Validate(); err != nil {
+	if err := h.workflowService.ValidateRequest(&request); err != nil {
 		logger.Error().Err(err).Str("deployment_type", string(request.DeploymentType)).Msg("Workflow request validation failed")
+		h.recordHistory(history, nil, err)
 		pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
 		return
 	}
@@ -48,24 +55,70 @@ func (h *Handler) Create(c *gin.Context) {
 		Str("deployment_type", string(request.DeploymentType)).
 		Msg("Creating workflow")
 
-	// Generate workflow YAML
-	yamlContent, err := h.workflowService.GenerateWorkflow(&request)
+	// Check for an existing file or pending PR of the same name
+	requestedName := request.WorkflowName
+	collision, err := h.workflowService.ResolveCollision(c.Request.Context(), accessToken, &request)
 	if err != nil {
-		logger.Error().Err(err).Str("workflow_name", request.WorkflowName).Msg("Failed to generate workflow YAML")
-		pkghttp.InternalServerErrorResponse(c, "Failed to generate workflow", err)
+		h.recordHistory(history, nil, err)
+		if errors.Is(err, domainWorkflow.ErrWorkflowAlreadyExists) {
+			pkghttp.ErrorResponse(c, http.StatusConflict, "Workflow already exists", err)
+			return
+		}
+		logger.Error().Err(err).Str("owner", request.Owner).Str("repo", request.Repository).Msg("Failed to check for existing workflow")
+		pkghttp.InternalServerErrorResponse(c, "Failed to check for existing workflow", err)
 		return
 	}
+	if request.WorkflowName != requestedName {
+		logger.Info().Str("requested_name", requestedName).Str("workflow_name", request.WorkflowName).Msg("Workflow name taken, using suffixed name")
+		history.WorkflowName = request.WorkflowName
+		history.RequestPayload, _ = json.Marshal(request.Redacted())
+	}
 
-	// Create workflow file in GitHub repository
-	response, err := h.workflowService.CreateWorkflow(
-		c.Request.Context(),
-		accessToken,
-		request.Owner,
-		request.Repository,
-		request.WorkflowName,
-		yamlContent,
-	)
+	// Reject direct commits before a branch or pull request is created
+	if err := h.workflowService.CheckDirectCommit(c.Request.Context(), accessToken, request.Owner, request.Repository, request.DirectCommit); err != nil {
+		h.recordHistory(history, nil, err)
+		if !respondChangeRejected(c, err) {
+			pkghttp.InternalServerErrorResponse(c, "Failed to check direct commit", err)
+		}
+		return
+	}
+
+	yamlContent, ok := h.buildWorkflow(c, accessToken, &request, history)
+	if !ok {
+		return
+	}
+
+	var response *domainWorkflow.Response
+	if collision != nil {
+		// Replace the existing file through an update PR
+		response, err = h.workflowService.UpdateWorkflow(c.Request.Context(), accessToken, &domainWorkflow.UpdateWorkflowRequest{
+			Owner:        request.Owner,
+			Repository:   request.Repository,
+			FilePath:     collision.FilePath,
+			Content:      yamlContent,
+			SHA:          collision.SHA,
+			PullRequest:  request.PullRequest,
+			DirectCommit: request.DirectCommit,
+			Request:      &request,
+		})
+	} else {
+		// Generate the files committed together with the workflow
+		auxiliary, auxErr := h.workflowService.GenerateAuxiliaryFiles(c.Request.Context(), accessToken, &request)
+		if auxErr != nil {
+			logger.Error().Err(auxErr).Str("owner", request.Owner).Str("repo", request.Repository).Msg("Failed to generate auxiliary files")
+			h.recordHistory(history, nil, auxErr)
+			pkghttp.InternalServerErrorResponse(c, "Failed to generate auxiliary files", auxErr)
+			return
+		}
+
+		// Create workflow file in GitHub repository
+		response, err = h.workflowService.CreateWorkflow(c.Request.Context(), accessToken, &request, yamlContent, auxiliary)
+	}
 	if err != nil {
+		h.recordHistory(history, nil, err)
+		if respondChangeRejected(c, err) {
+			return
+		}
 		logger.Error().
 			Err(err).
 			Str("owner", request.Owner).
@@ -76,6 +129,13 @@ func (h *Handler) Create(c *gin.Context) {
 		return
 	}
 
+	secrets, ok := h.storeDotEnvSecrets(c, accessToken, &request, history, response)
+	if !ok {
+		return
+	}
+	response.Secrets = secrets
+	h.recordHistory(history, response, nil)
+
 	logger.Info().
 		Str("owner", request.Owner).
 		Str("repo", request.Repository).
@@ -85,3 +145,61 @@ func (h *Handler) Create(c *gin.Context) {
 
 	pkghttp.SuccessResponse(c, http.StatusCreated, response.Message, response)
 }
+
+// buildWorkflow verifies the project paths of a validated request and generates
+// its YAML. Failures are recorded in history and written to the response, in
+// which case ok is false.
+func (h *Handler) buildWorkflow(c *gin.Context, accessToken string, request *domainWorkflow.Request, history *domainWorkflow.History) (string, bool) {
+	// Make sure the Docker paths exist before a branch and PR are created
+	if err := h.workflowService.VerifyProjectPaths(c.Request.Context(), accessToken, request); err != nil {
+		h.recordHistory(history, nil, err)
+		if errors.Is(err, domainWorkflow.ErrProjectPathNotFound) {
+			pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
+			return "", false
+		}
+		logger.Error().Err(err).Str("owner", request.Owner).Str("repo", request.Repository).Msg("Failed to verify project paths")
+		pkghttp.InternalServerErrorResponse(c, "Failed to verify project paths", err)
+		return "", false
+	}
+
+	// Generate workflow YAML
+	yamlContent, err := h.workflowService.GenerateWorkflow(request)
+	history.YAMLContent = yamlContent
+	var validationErr *domainWorkflow.ValidationError
+	if errors.As(err, &validationErr) {
+		h.recordHistory(history, nil, err)
+		logger.Error().Err(err).Str("workflow_name", request.WorkflowName).Msg("Generated workflow YAML failed validation")
+		pkghttp.ValidationErrorResponse(c, "Generated workflow is invalid", err, gin.H{
+			"yaml_content": yamlContent,
+			"diagnostics":  validationErr.Diagnostics,
+		})
+		return "", false
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("workflow_name", request.WorkflowName).Msg("Failed to generate workflow YAML")
+		h.recordHistory(history, nil, err)
+		pkghttp.InternalServerErrorResponse(c, "Failed to generate workflow", err)
+		return "", false
+	}
+
+	return yamlContent, true
+}
+
+// storeDotEnvSecrets stores dotenv credentials as the secrets the committed
+// workflow references. It runs once the commit succeeded so that a failed
+// change never overwrites existing secrets. Failures are recorded in history
+// and written to the response, in which case ok is false.
+func (h *Handler) storeDotEnvSecrets(c *gin.Context, accessToken string, request *domainWorkflow.Request, history *domainWorkflow.History, response *domainWorkflow.Response) ([]domainWorkflow.DotEnvSecret, bool) {
+	secrets, err := h.workflowService.StoreDotEnvSecrets(c.Request.Context(), accessToken, request)
+	if err != nil {
+		logger.Error().Err(err).
+			Str("owner", request.Owner).
+			Str("repo", request.Repository).
+			Str("file_path", response.FilePath).
+			Msg("Failed to store dotenv secrets of committed workflow")
+		h.recordHistory(history, response, err)
+		pkghttp.InternalServerErrorResponse(c, "Workflow was committed but its dotenv secrets could not be stored; regenerate it to retry", err)
+		return nil, false
+	}
+	return secrets, true
+}
@@ -0,0 +1,19 @@
+package workflow
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+)
+
+// ListDeploymentTypes returns the registered deployment types and their field schemas
+// GET /api/workflows/deployment-types
+func (h *Handler) ListDeploymentTypes(c *gin.Context) {
+	deploymentTypes := h.workflowService.DeploymentTypes()
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Deployment types fetched successfully", gin.H{
+		"deployment_types": deploymentTypes,
+		"count":            len(deploymentTypes),
+	})
+}
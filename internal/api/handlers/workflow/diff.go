@@ -0,0 +1,50 @@
+package workflow
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domainWorkflow "github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// Diff compares workflow content with the file on the default branch before
+// it is submitted as an update
+// POST /api/workflows/:owner/:repo/diff
+func (h *Handler) Diff(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		pkghttp.UnauthorizedResponse(c, "User not found in context")
+		return
+	}
+
+	var req domainWorkflow.DiffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkghttp.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	accessToken, err := h.getAccessToken(userID.(string))
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
+		return
+	}
+
+	diff, err := h.workflowService.DiffWorkflowFile(c.Request.Context(), accessToken, owner, repo, req.FilePath, req.Content)
+	if errors.Is(err, domainWorkflow.ErrInvalidWorkflowPath) {
+		pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("owner", owner).Str("repo", repo).Str("file_path", req.FilePath).Msg("Failed to diff workflow")
+		pkghttp.InternalServerErrorResponse(c, "Failed to diff workflow", err)
+		return
+	}
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Workflow diff generated successfully", diff)
+}
@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domainWorkflow "github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// DetectDrift reports generated workflows of an organization that no longer
+// match the request they were generated from
+// GET /api/organizations/:org/workflows/drift?repository=api&repository=web
+func (h *Handler) DetectDrift(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		pkghttp.UnauthorizedResponse(c, "User not found in context")
+		return
+	}
+
+	org := c.Param("org")
+	if org == "" {
+		pkghttp.BadRequestResponse(c, "Organization name is required")
+		return
+	}
+
+	accessToken, err := h.getAccessToken(userID.(string))
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
+		return
+	}
+
+	histories, err := h.historyRepository.ListLatestRequests(org)
+	if err != nil {
+		logger.Error().Err(err).Str("organization", org).Msg("Failed to read workflow history")
+		pkghttp.InternalServerErrorResponse(c, "Failed to read workflow history", err)
+		return
+	}
+
+	stored := make([]domainWorkflow.StoredRequest, 0, len(histories))
+	for _, history := range histories {
+		var request domainWorkflow.Request
+		if err := json.Unmarshal(history.RequestPayload, &request); err != nil {
+			logger.Warn().Err(err).Str("history_id", history.ID.String()).Msg("Skipping unreadable workflow request")
+			continue
+		}
+		stored = append(stored, domainWorkflow.StoredRequest{
+			Repository:   history.Repository,
+			WorkflowName: history.WorkflowName,
+			Request:      &request,
+		})
+	}
+
+	report, err := h.workflowService.DetectDrift(c.Request.Context(), accessToken, org, c.QueryArray("repository"), stored)
+	if err != nil {
+		logger.Error().Err(err).Str("organization", org).Msg("Failed to detect workflow drift")
+		pkghttp.InternalServerErrorResponse(c, "Failed to detect workflow drift", err)
+		return
+	}
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Workflow drift report generated successfully", report)
+}
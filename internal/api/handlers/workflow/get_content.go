@@ -1,10 +1,12 @@
 package workflow
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	domainWorkflow "github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
 	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
 )
 
@@ -44,6 +46,10 @@ func (h *Handler) GetWorkflowContent(c *gin.Context) {
 	}
 
 	fileContent, err := h.workflowService.GetWorkflowContent(c.Request.Context(), accessToken, owner, repo, filePath)
+	if errors.Is(err, domainWorkflow.ErrInvalidWorkflowPath) {
+		pkghttp.BadRequestResponse(c, err.Error())
+		return
+	}
 	if err != nil {
 		pkghttp.InternalServerErrorResponse(c, "Failed to fetch workflow file content", err)
 		return
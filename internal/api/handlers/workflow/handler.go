@@ -1,25 +1,44 @@
 package workflow
 
 import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
 	database "github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/database/repositories"
+	"github.com/vmaurya-21/Calance-Workflow/internal/middleware"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
 )
 
 // Handler handles workflow-related HTTP requests
 type Handler struct {
-	workflowService *workflow.Service
-	tokenRepository *database.TokenRepository
+	workflowService       *workflow.Service
+	tokenRepository       *database.TokenRepository
+	historyRepository     *database.HistoryRepository
+	pullRequestRepository *database.PullRequestRepository
+	webhookSecret         string
+	admins                []string
 }
 
-// NewHandler creates a new workflow handler
+// NewHandler creates a new workflow handler. webhookSecret verifies GitHub
+// webhook deliveries; webhooks are rejected when it is empty. admins are the
+// GitHub logins allowed to read other users' records.
 func NewHandler(
 	workflowService *workflow.Service,
 	tokenRepo *database.TokenRepository,
+	historyRepo *database.HistoryRepository,
+	pullRequestRepo *database.PullRequestRepository,
+	webhookSecret string,
+	admins []string,
 ) *Handler {
 	return &Handler{
-		workflowService: workflowService,
-		tokenRepository: tokenRepo,
+		workflowService:       workflowService,
+		tokenRepository:       tokenRepo,
+		historyRepository:     historyRepo,
+		pullRequestRepository: pullRequestRepo,
+		webhookSecret:         webhookSecret,
+		admins:                admins,
 	}
 }
 
@@ -28,6 +47,50 @@ func (h *Handler) getUserID(c interface{}) (uuid.UUID, error) {
 	return uuid.Nil, nil
 }
 
+// callerID returns the authenticated user's ID, responding with 401 when the
+// context holds no valid user ID
+func (h *Handler) callerID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		pkghttp.UnauthorizedResponse(c, "User not found in context")
+		return uuid.Nil, false
+	}
+	id, ok := value.(string)
+	if !ok {
+		pkghttp.UnauthorizedResponse(c, "Invalid user in context")
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Invalid user in context")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// scopeToCaller returns the user whose records a list request may read: the
+// requested user for admins, otherwise the caller. Other users' records are
+// refused to non-admins, in which case ok is false.
+func (h *Handler) scopeToCaller(c *gin.Context, callerID uuid.UUID, requested string) (*uuid.UUID, bool) {
+	if middleware.IsAdmin(c, h.admins) {
+		if requested == "" {
+			return nil, true
+		}
+		userID, err := uuid.Parse(requested)
+		if err != nil {
+			pkghttp.BadRequestResponse(c, "Invalid user_id")
+			return nil, false
+		}
+		return &userID, true
+	}
+
+	if requested != "" && requested != callerID.String() {
+		pkghttp.ForbiddenResponse(c, "Only admins can read other users' records")
+		return nil, false
+	}
+	return &callerID, true
+}
+
 // getAccessToken retrieves access token for user
 func (h *Handler) getAccessToken(userID string) (string, error) {
 	userUUID, err := uuid.Parse(userID)
@@ -41,3 +104,18 @@ func (h *Handler) getAccessToken(userID string) (string, error) {
 	}
 	return token.AccessToken, nil
 }
+
+// respondChangeRejected writes the response for workflow changes rejected
+// because of their pull request or direct commit options and reports whether
+// err was such a rejection
+func respondChangeRejected(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, workflow.ErrDirectCommitNotAllowed), errors.Is(err, workflow.ErrBranchProtected):
+		pkghttp.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, workflow.ErrInvalidPullRequestOptions), errors.Is(err, workflow.ErrInvalidDirectCommit):
+		pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package workflow
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainWorkflow "github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// maxHistoryLimit caps the number of history entries returned at once
+const maxHistoryLimit = 200
+
+// historyDateLayout is accepted by the since/until filters next to RFC 3339
+const historyDateLayout = "2006-01-02"
+
+// newHistory starts a history entry for a workflow change attempted by userID
+func newHistory(userID string, action domainWorkflow.HistoryAction, owner, repo string, payload interface{}) *domainWorkflow.History {
+	history := &domainWorkflow.History{
+		Action:     action,
+		Owner:      owner,
+		Repository: repo,
+	}
+	history.UserID, _ = uuid.Parse(userID)
+
+	if payload != nil {
+		if data, err := json.Marshal(payload); err == nil {
+			history.RequestPayload = data
+		}
+	}
+	return history
+}
+
+// recordHistory saves a history entry with the outcome of the attempt and
+// starts tracking the pull request it opened. A failure to save is logged and
+// does not affect the response.
+func (h *Handler) recordHistory(history *domainWorkflow.History, response *domainWorkflow.Response, err error) {
+	if err != nil {
+		message := err.Error()
+		history.Status = domainWorkflow.HistoryStatusFailed
+		history.ErrorMessage = &message
+	} else {
+		history.Status = domainWorkflow.HistoryStatusSuccess
+	}
+
+	if response != nil {
+		history.FilePath = response.FilePath
+		history.ContentSHA = response.ContentSHA
+		history.PullRequestURL = response.FileURL
+		history.PullRequestNumber = response.PRNumber
+	}
+
+	if saveErr := h.historyRepository.Create(history); saveErr != nil {
+		logger.Error().Err(saveErr).
+			Str("owner", history.Owner).
+			Str("repo", history.Repository).
+			Str("workflow_name", history.WorkflowName).
+			Msg("Failed to record workflow history")
+	}
+
+	if err != nil {
+		return
+	}
+	if pr := domainWorkflow.NewTrackedPullRequest(history.UserID, history.Action, response); pr != nil {
+		if saveErr := h.pullRequestRepository.Create(pr); saveErr != nil {
+			logger.Error().Err(saveErr).
+				Str("owner", pr.Owner).
+				Str("repo", pr.Repository).
+				Int("pr_number", pr.Number).
+				Msg("Failed to track pull request")
+		}
+	}
+}
+
+// ListHistory lists the caller's recorded workflow create and update attempts.
+// Admins may list every user's attempts, or another user's with user_id.
+// GET /api/workflows/history?owner=&repository=&user_id=&status=&action=&since=&until=&limit=
+func (h *Handler) ListHistory(c *gin.Context) {
+	callerID, ok := h.callerID(c)
+	if !ok {
+		return
+	}
+
+	filter := domainWorkflow.HistoryFilter{
+		Owner:      c.Query("owner"),
+		Repository: c.Query("repository"),
+		Status:     c.Query("status"),
+		Action:     domainWorkflow.HistoryAction(c.Query("action")),
+	}
+
+	if filter.Status != "" && filter.Status != domainWorkflow.HistoryStatusSuccess && filter.Status != domainWorkflow.HistoryStatusFailed {
+		pkghttp.BadRequestResponse(c, "status must be 'success' or 'failed'")
+		return
+	}
+
+	userID, ok := h.scopeToCaller(c, callerID, c.Query("user_id"))
+	if !ok {
+		return
+	}
+	filter.UserID = userID
+
+	var err error
+	if filter.Since, err = parseHistoryTime(c.Query("since"), false); err != nil {
+		pkghttp.BadRequestResponse(c, "Invalid since: use RFC 3339 or YYYY-MM-DD")
+		return
+	}
+	if filter.Until, err = parseHistoryTime(c.Query("until"), true); err != nil {
+		pkghttp.BadRequestResponse(c, "Invalid until: use RFC 3339 or YYYY-MM-DD")
+		return
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxHistoryLimit {
+			pkghttp.BadRequestResponse(c, "limit must be between 1 and "+strconv.Itoa(maxHistoryLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	histories, err := h.historyRepository.List(filter)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list workflow history")
+		pkghttp.InternalServerErrorResponse(c, "Failed to list workflow history", err)
+		return
+	}
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Workflow history retrieved successfully", gin.H{
+		"history": histories,
+		"count":   len(histories),
+	})
+}
+
+// parseHistoryTime parses an RFC 3339 timestamp or a date. A date used as an
+// upper bound covers the whole day.
+func parseHistoryTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse(historyDateLayout, value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
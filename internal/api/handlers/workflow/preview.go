@@ -9,11 +9,12 @@ import (
 	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
 )
 
-// Preview generates and returns the workflow YAML without creating it
+// Preview generates and returns the workflow YAML without creating it. When
+// the workflow file already exists the response includes a diff against it.
 // POST /api/workflows/preview
 func (h *Handler) Preview(c *gin.Context) {
 	// Get user ID from context (for authentication)
-	_, exists := c.Get("user_id")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		pkghttp.UnauthorizedResponse(c, "User not found in context")
 		return
@@ -28,7 +29,7 @@ func (h *Handler) Preview(c *gin.Context) {
 	}
 
 	// Validate the request
-	if err := request.Validate(); err != nil {
+	if err := h.workflowService.ValidateRequest(&request); err != nil {
 		logger.Error().Err(err).Str("deployment_type", string(request.DeploymentType)).Msg("Workflow preview request validation failed")
 		pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
 		return
@@ -39,19 +40,35 @@ func (h *Handler) Preview(c *gin.Context) {
 		Str("deployment_type", string(request.DeploymentType)).
 		Msg("Previewing workflow")
 
-	// Generate workflow YAML
-	yamlContent, err := h.workflowService.GenerateWorkflow(&request)
+	// Generate workflow YAML and validate it
+	preview, err := h.workflowService.PreviewWorkflow(&request)
 	if err != nil {
 		logger.Error().Err(err).Str("workflow_name", request.WorkflowName).Msg("Failed to preview workflow YAML")
 		pkghttp.InternalServerErrorResponse(c, "Failed to generate workflow preview", err)
 		return
 	}
 
+	// A missing diff does not prevent the preview
+	if accessToken, err := h.getAccessToken(userID.(string)); err == nil && accessToken != "" {
+		filePath := domainWorkflow.WorkflowFilePath(request.WorkflowName)
+		diff, err := h.workflowService.DiffWorkflowFile(c.Request.Context(), accessToken, request.Owner, request.Repository, filePath, preview.YAMLContent)
+		if err != nil {
+			logger.Warn().Err(err).Str("owner", request.Owner).Str("repo", request.Repository).Msg("Failed to diff workflow preview")
+		} else if diff.Exists {
+			preview.Diff = diff
+		}
+	}
+
+	if !preview.Valid {
+		logger.Warn().
+			Str("workflow_name", request.WorkflowName).
+			Int("diagnostic_count", len(preview.Diagnostics)).
+			Msg("Workflow preview generated with validation errors")
+		pkghttp.SuccessResponse(c, http.StatusOK, "Workflow preview generated with validation errors", preview)
+		return
+	}
+
 	logger.Info().Str("workflow_name", request.WorkflowName).Msg("Workflow preview generated successfully")
 
-	pkghttp.SuccessResponse(c, http.StatusOK, "Workflow preview generated successfully", gin.H{
-		"workflow_name":   request.WorkflowName,
-		"deployment_type": request.DeploymentType,
-		"yaml_content":    yamlContent,
-	})
+	pkghttp.SuccessResponse(c, http.StatusOK, "Workflow preview generated successfully", preview)
 }
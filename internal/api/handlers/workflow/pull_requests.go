@@ -0,0 +1,219 @@
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainWorkflow "github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// maxPullRequestLimit caps the number of tracked pull requests returned at once
+const maxPullRequestLimit = 200
+
+// maxConcurrentRefreshes bounds the pull requests refreshed from GitHub at once
+const maxConcurrentRefreshes = 4
+
+// maxWebhookBodySize caps webhook payloads; GitHub sends at most 25 MB
+const maxWebhookBodySize = 25 << 20
+
+// ListPullRequests lists the pull requests the caller opened through the
+// workflow manager; admins may list every user's, or another user's with
+// user_id. With refresh=true the caller's listed pull requests that are not
+// merged are first refreshed from GitHub with the caller's token.
+// GET /api/workflows/pull-requests?owner=&repository=&workflow_name=&user_id=&state=&limit=&refresh=
+func (h *Handler) ListPullRequests(c *gin.Context) {
+	callerID, ok := h.callerID(c)
+	if !ok {
+		return
+	}
+
+	filter := domainWorkflow.PullRequestFilter{
+		Owner:        c.Query("owner"),
+		Repository:   c.Query("repository"),
+		WorkflowName: c.Query("workflow_name"),
+		State:        c.Query("state"),
+	}
+
+	switch filter.State {
+	case "", domainWorkflow.PullRequestStateOpen, domainWorkflow.PullRequestStateMerged, domainWorkflow.PullRequestStateClosed:
+	default:
+		pkghttp.BadRequestResponse(c, "state must be 'open', 'merged' or 'closed'")
+		return
+	}
+
+	scopedUserID, ok := h.scopeToCaller(c, callerID, c.Query("user_id"))
+	if !ok {
+		return
+	}
+	filter.UserID = scopedUserID
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPullRequestLimit {
+			pkghttp.BadRequestResponse(c, "limit must be between 1 and "+strconv.Itoa(maxPullRequestLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	refresh := false
+	if value := c.Query("refresh"); value != "" {
+		var err error
+		if refresh, err = strconv.ParseBool(value); err != nil {
+			pkghttp.BadRequestResponse(c, "refresh must be true or false")
+			return
+		}
+	}
+
+	pulls, err := h.pullRequestRepository.List(filter)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list tracked pull requests")
+		pkghttp.InternalServerErrorResponse(c, "Failed to list pull requests", err)
+		return
+	}
+
+	var warnings []string
+	if refresh {
+		accessToken, err := h.getAccessToken(callerID.String())
+		if err != nil {
+			pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
+			return
+		}
+
+		warnings = h.refreshOwnPullRequests(c, accessToken, callerID, pulls)
+
+		// Refreshed pull requests may no longer match the state filter
+		if filter.State != "" {
+			matching := pulls[:0]
+			for _, pr := range pulls {
+				if pr.State == filter.State {
+					matching = append(matching, pr)
+				}
+			}
+			pulls = matching
+		}
+	}
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Pull requests retrieved successfully", gin.H{
+		"pullRequests": pulls,
+		"count":        len(pulls),
+		"warnings":     warnings,
+	})
+}
+
+// GitHubWebhook refreshes the tracked pull requests named by a GitHub webhook
+// delivery, using the token of the user who opened each. Subscribe the webhook
+// to pull request, pull request review, check suite, check run and status events.
+// POST /api/webhooks/github
+func (h *Handler) GitHubWebhook(c *gin.Context) {
+	if h.webhookSecret == "" {
+		pkghttp.ForbiddenResponse(c, "Webhooks are not configured")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize))
+	if err != nil {
+		pkghttp.BadRequestResponse(c, "Failed to read request body")
+		return
+	}
+	if !github.VerifyWebhookSignature(h.webhookSecret, c.GetHeader("X-Hub-Signature-256"), body) {
+		pkghttp.UnauthorizedResponse(c, "Invalid webhook signature")
+		return
+	}
+
+	eventType := c.GetHeader("X-GitHub-Event")
+	event, err := github.ParseWebhookEvent(eventType, body)
+	if err != nil {
+		pkghttp.BadRequestResponse(c, "Invalid webhook payload: "+err.Error())
+		return
+	}
+	if event == nil {
+		pkghttp.SuccessResponse(c, http.StatusOK, "Event ignored", gin.H{"refreshed": 0})
+		return
+	}
+
+	pulls, err := h.pullRequestRepository.FindForEvent(event.Owner, event.Repository, event.PullRequests, event.HeadSHA)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to find tracked pull requests")
+		pkghttp.InternalServerErrorResponse(c, "Failed to find pull requests", err)
+		return
+	}
+
+	refreshed := 0
+	for i := range pulls {
+		pr := &pulls[i]
+		token, err := h.tokenRepository.FindByUserID(pr.UserID)
+		if err != nil || token == nil {
+			logger.Warn().Str("owner", pr.Owner).Str("repo", pr.Repository).Int("pr_number", pr.Number).
+				Msg("No token to refresh tracked pull request")
+			continue
+		}
+		if err := h.refreshPullRequest(c, token.AccessToken, pr); err != nil {
+			continue
+		}
+		refreshed++
+	}
+
+	logger.Info().
+		Str("event", eventType).
+		Str("owner", event.Owner).
+		Str("repo", event.Repository).
+		Int("refreshed", refreshed).
+		Msg("Processed GitHub webhook")
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Webhook processed", gin.H{"refreshed": refreshed})
+}
+
+// refreshOwnPullRequests refreshes the pull requests of pulls that callerID
+// opened and that are not merged, a few at a time. Other users' pull requests
+// are left as stored, since the caller's token may not reach their
+// repositories. It returns a warning for each failed refresh.
+func (h *Handler) refreshOwnPullRequests(c *gin.Context, token string, callerID uuid.UUID, pulls []domainWorkflow.TrackedPullRequest) []string {
+	var (
+		mu       sync.Mutex
+		warnings []string
+		wg       sync.WaitGroup
+	)
+	slots := make(chan struct{}, maxConcurrentRefreshes)
+	for i := range pulls {
+		pr := &pulls[i]
+		if pr.UserID != callerID || pr.State == domainWorkflow.PullRequestStateMerged {
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			if err := h.refreshPullRequest(c, token, pr); err != nil {
+				mu.Lock()
+				warnings = append(warnings, fmt.Sprintf("%s/%s#%d: %v", pr.Owner, pr.Repository, pr.Number, err))
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return warnings
+}
+
+// refreshPullRequest refreshes a tracked pull request from GitHub and saves it
+func (h *Handler) refreshPullRequest(c *gin.Context, token string, pr *domainWorkflow.TrackedPullRequest) error {
+	if err := h.workflowService.RefreshPullRequest(c.Request.Context(), token, pr); err != nil {
+		logger.Error().Err(err).Str("owner", pr.Owner).Str("repo", pr.Repository).Int("pr_number", pr.Number).
+			Msg("Failed to refresh tracked pull request")
+		return err
+	}
+	if err := h.pullRequestRepository.Update(pr); err != nil {
+		logger.Error().Err(err).Str("owner", pr.Owner).Str("repo", pr.Repository).Int("pr_number", pr.Number).
+			Msg("Failed to save tracked pull request")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package workflow
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// ListTemplates returns the loaded workflow templates and their version
+// GET /api/templates
+func (h *Handler) ListTemplates(c *gin.Context) {
+	templates, status := h.workflowService.Templates()
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Templates fetched successfully", gin.H{
+		"source":            status.Source,
+		"configured_source": status.ConfiguredSource,
+		"version":           status.Version,
+		"loaded_at":         status.LoadedAt,
+		"last_error":        status.LastError,
+		"templates":         templates,
+		"count":             len(templates),
+	})
+}
+
+// ReloadTemplates reloads workflow templates from the configured source.
+// Only admins may reload, since the templates are shared by all users.
+// POST /api/templates/reload
+func (h *Handler) ReloadTemplates(c *gin.Context) {
+	if err := h.workflowService.ReloadTemplates(c.Request.Context()); err != nil {
+		logger.Error().Err(err).Msg("Failed to reload workflow templates")
+		pkghttp.InternalServerErrorResponse(c, "Failed to reload templates", err)
+		return
+	}
+
+	_, status := h.workflowService.Templates()
+	logger.Info().Str("source", status.Source).Str("version", status.Version).Msg("Workflow templates reloaded")
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Templates reloaded successfully", status)
+}
@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -45,7 +46,27 @@ func (h *Handler) UpdateWorkflow(c *gin.Context) {
 		return
 	}
 
+	history := newHistory(userID.(string), workflow.HistoryActionUpdate, req.Owner, req.Repository, req)
+	history.WorkflowName = workflow.WorkflowNameFromPath(req.FilePath)
+	history.FilePath = req.FilePath
+	history.YAMLContent = req.Content
+
 	response, err := h.workflowService.UpdateWorkflow(c.Request.Context(), accessToken, &req)
+	h.recordHistory(history, response, err)
+	var validationErr *workflow.ValidationError
+	if errors.As(err, &validationErr) {
+		pkghttp.ValidationErrorResponse(c, "Workflow is invalid", err, gin.H{
+			"diagnostics": validationErr.Diagnostics,
+		})
+		return
+	}
+	if errors.Is(err, workflow.ErrInvalidWorkflowPath) {
+		pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
+		return
+	}
+	if respondChangeRejected(c, err) {
+		return
+	}
 	if err != nil {
 		pkghttp.InternalServerErrorResponse(c, "Failed to update workflow", err)
 		return
@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	domainWorkflow "github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	"github.com/vmaurya-21/Calance-Workflow/internal/middleware"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// StartUpgrade starts upgrading the generated workflows of an organization to
+// a new release tag. Each upgrade pull request is recorded in the workflow
+// history and tracked like those of create and update requests.
+// POST /api/workflows/upgrades
+func (h *Handler) StartUpgrade(c *gin.Context) {
+	callerID, ok := h.callerID(c)
+	if !ok {
+		return
+	}
+
+	var request domainWorkflow.UpgradeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		pkghttp.BadRequestResponse(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	accessToken, err := h.getAccessToken(callerID.String())
+	if err != nil {
+		pkghttp.UnauthorizedResponse(c, "Access token not found. Please login again.")
+		return
+	}
+
+	record := func(response *domainWorkflow.Response, err error) {
+		history := newHistory(callerID.String(), domainWorkflow.HistoryActionUpgrade, response.Owner, response.Repository, request)
+		history.WorkflowName = response.WorkflowName
+		h.recordHistory(history, response, err)
+	}
+
+	job, err := h.workflowService.StartUpgrade(accessToken, callerID, &request, record)
+	if err != nil {
+		pkghttp.BadRequestResponse(c, "Validation failed: "+err.Error())
+		return
+	}
+
+	logger.Info().
+		Str("organization", request.Organization).
+		Str("release_tag", request.ReleaseTag).
+		Str("job_id", job.ID.String()).
+		Msg("Started workflow upgrade")
+
+	pkghttp.SuccessResponse(c, http.StatusAccepted, "Workflow upgrade started", job)
+}
+
+// GetUpgrade returns the progress and per-repository results of an upgrade
+// job. Only the user who started it and admins can read a job; others get 404.
+// GET /api/workflows/upgrades/:id
+func (h *Handler) GetUpgrade(c *gin.Context) {
+	callerID, ok := h.callerID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		pkghttp.BadRequestResponse(c, "Invalid upgrade job ID")
+		return
+	}
+
+	job, err := h.workflowService.GetUpgradeJob(id)
+	if err == nil && job.UserID != callerID && !middleware.IsAdmin(c, h.admins) {
+		err = domainWorkflow.ErrUpgradeJobNotFound
+	}
+	if errors.Is(err, domainWorkflow.ErrUpgradeJobNotFound) {
+		pkghttp.NotFoundResponse(c, err.Error())
+		return
+	}
+	if err != nil {
+		pkghttp.InternalServerErrorResponse(c, "Failed to get upgrade job", err)
+		return
+	}
+
+	pkghttp.SuccessResponse(c, http.StatusOK, "Upgrade job retrieved successfully", job)
+}
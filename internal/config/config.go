@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -11,12 +12,17 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	GitHub   GitHubConfig
-	JWT      JWTConfig
-	Frontend FrontendConfig
-	Log      LogConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	GitHub    GitHubConfig
+	JWT       JWTConfig
+	Frontend  FrontendConfig
+	Log       LogConfig
+	Templates TemplatesConfig
+	Janitor   JanitorConfig
+	Policy    PolicyConfig
+	HTTP      HTTPClientConfig
+	Admin     AdminConfig
 }
 
 type ServerConfig struct {
@@ -38,6 +44,20 @@ type GitHubConfig struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURL  string
+	// WebhookSecret verifies webhook deliveries; webhooks are rejected when empty
+	WebhookSecret string
+	// RateLimitPolicy is "wait" to hold requests of a token with an exhausted
+	// rate limit until it resets, for at most RateLimitMaxWaitSeconds, or
+	// "fail" to fail them right away
+	RateLimitPolicy         string
+	RateLimitMaxWaitSeconds int
+	// ResponseCache stores GET responses to revalidate them with conditional
+	// requests: "memory" keeps up to ResponseCacheMemoryMB in memory, "postgres"
+	// keeps responses refreshed within ResponseCacheMaxAgeHours in the database,
+	// and "none" disables it
+	ResponseCache            string
+	ResponseCacheMemoryMB    int
+	ResponseCacheMaxAgeHours int
 }
 
 type JWTConfig struct {
@@ -55,6 +75,53 @@ type LogConfig struct {
 	Format string
 }
 
+// TemplatesConfig controls where workflow templates are loaded from.
+// GitURL takes precedence over Dir; with neither set the built-in templates are used.
+type TemplatesConfig struct {
+	Dir                   string
+	GitURL                string
+	GitRef                string
+	GitPath               string
+	CacheDir              string
+	ReloadIntervalSeconds int
+}
+
+// JanitorConfig controls the cleanup of workflow branches left without an open
+// pull request. An interval of zero disables it.
+type JanitorConfig struct {
+	IntervalMinutes int
+	MaxAgeHours     int
+}
+
+// PolicyConfig holds server-side rules for workflow changes.
+// DirectCommitRepositories lists owner/repository patterns (e.g. "acme/sandbox-*")
+// whose workflows may be committed without a pull request.
+type PolicyConfig struct {
+	DirectCommitRepositories []string
+}
+
+// HTTPClientConfig controls outgoing HTTP requests, such as those to the GitHub API.
+// Idempotent requests are retried up to RetryMaxAttempts times in total after
+// connection failures and 5xx responses, with exponential backoff.
+type HTTPClientConfig struct {
+	TimeoutSeconds            int
+	RetryMaxAttempts          int
+	RetryInitialBackoffMillis int
+	RetryMaxBackoffMillis     int
+	RetryMaxElapsedSeconds    int
+	MaxIdleConns              int
+	MaxIdleConnsPerHost       int
+	MaxConnsPerHost           int
+	IdleConnTimeoutSeconds    int
+}
+
+// AdminConfig lists the GitHub logins allowed to manage server-wide state,
+// such as reloading templates, and to read other users' workflow history and
+// pull requests
+type AdminConfig struct {
+	Users []string
+}
+
 var AppConfig *Config
 
 // LoadConfig loads configuration from environment variables
@@ -79,9 +146,15 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		GitHub: GitHubConfig{
-			ClientID:     getEnv("GITHUB_CLIENT_ID", ""),
-			ClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
-			RedirectURL:  getEnv("GITHUB_REDIRECT_URL", "http://localhost:8080/api/auth/github/callback"),
+			ClientID:                 getEnv("GITHUB_CLIENT_ID", ""),
+			ClientSecret:             getEnv("GITHUB_CLIENT_SECRET", ""),
+			RedirectURL:              getEnv("GITHUB_REDIRECT_URL", "http://localhost:8080/api/auth/github/callback"),
+			WebhookSecret:            getEnv("GITHUB_WEBHOOK_SECRET", ""),
+			RateLimitPolicy:          getEnv("GITHUB_RATE_LIMIT_POLICY", "wait"),
+			RateLimitMaxWaitSeconds:  getEnvAsInt("GITHUB_RATE_LIMIT_MAX_WAIT_SECONDS", 60),
+			ResponseCache:            getEnv("GITHUB_RESPONSE_CACHE", "memory"),
+			ResponseCacheMemoryMB:    getEnvAsInt("GITHUB_RESPONSE_CACHE_MEMORY_MB", 64),
+			ResponseCacheMaxAgeHours: getEnvAsInt("GITHUB_RESPONSE_CACHE_MAX_AGE_HOURS", 168),
 		},
 		JWT: JWTConfig{
 			Secret:          getEnv("JWT_SECRET", ""),
@@ -95,6 +168,35 @@ func LoadConfig() (*Config, error) {
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
+		Templates: TemplatesConfig{
+			Dir:                   getEnv("TEMPLATES_DIR", ""),
+			GitURL:                getEnv("TEMPLATES_GIT_URL", ""),
+			GitRef:                getEnv("TEMPLATES_GIT_REF", "main"),
+			GitPath:               getEnv("TEMPLATES_GIT_PATH", "."),
+			CacheDir:              getEnv("TEMPLATES_CACHE_DIR", filepath.Join(os.TempDir(), "calance-workflow-templates")),
+			ReloadIntervalSeconds: getEnvAsInt("TEMPLATES_RELOAD_INTERVAL_SECONDS", 60),
+		},
+		Janitor: JanitorConfig{
+			IntervalMinutes: getEnvAsInt("BRANCH_JANITOR_INTERVAL_MINUTES", 60),
+			MaxAgeHours:     getEnvAsInt("BRANCH_JANITOR_MAX_AGE_HOURS", 72),
+		},
+		Policy: PolicyConfig{
+			DirectCommitRepositories: getEnvAsSlice("DIRECT_COMMIT_REPOSITORIES", nil),
+		},
+		Admin: AdminConfig{
+			Users: getEnvAsSlice("ADMIN_USERS", nil),
+		},
+		HTTP: HTTPClientConfig{
+			TimeoutSeconds:            getEnvAsInt("HTTP_CLIENT_TIMEOUT_SECONDS", 30),
+			RetryMaxAttempts:          getEnvAsInt("HTTP_CLIENT_RETRY_MAX_ATTEMPTS", 3),
+			RetryInitialBackoffMillis: getEnvAsInt("HTTP_CLIENT_RETRY_INITIAL_BACKOFF_MS", 200),
+			RetryMaxBackoffMillis:     getEnvAsInt("HTTP_CLIENT_RETRY_MAX_BACKOFF_MS", 5000),
+			RetryMaxElapsedSeconds:    getEnvAsInt("HTTP_CLIENT_RETRY_MAX_ELAPSED_SECONDS", 30),
+			MaxIdleConns:              getEnvAsInt("HTTP_CLIENT_MAX_IDLE_CONNS", 100),
+			MaxIdleConnsPerHost:       getEnvAsInt("HTTP_CLIENT_MAX_IDLE_CONNS_PER_HOST", 10),
+			MaxConnsPerHost:           getEnvAsInt("HTTP_CLIENT_MAX_CONNS_PER_HOST", 0),
+			IdleConnTimeoutSeconds:    getEnvAsInt("HTTP_CLIENT_IDLE_CONN_TIMEOUT_SECONDS", 90),
+		},
 	}
 
 	// Validate required fields
@@ -117,6 +219,9 @@ func (c *Config) Validate() error {
 	if c.JWT.Secret == "" {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
+	if c.Janitor.IntervalMinutes > 0 && c.Janitor.MaxAgeHours <= 0 {
+		return fmt.Errorf("BRANCH_JANITOR_MAX_AGE_HOURS must be positive")
+	}
 	if c.Database.Password == "" {
 		log.Println("Warning: DB_PASSWORD is empty")
 	}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/vmaurya-21/Calance-Workflow/internal/config"
 	"github.com/vmaurya-21/Calance-Workflow/internal/domain/auth"
+	"github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
 	"github.com/vmaurya-21/Calance-Workflow/internal/logger"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -50,6 +52,9 @@ func runMigrations() error {
 	err := DB.AutoMigrate(
 		&auth.User{},
 		&auth.Token{},
+		&workflow.History{},
+		&workflow.TrackedPullRequest{},
+		&github.CachedResponse{},
 		// Add other models here as needed
 	)
 
@@ -46,15 +46,25 @@ func (s *Service) GetOrganizationRepositories(ctx context.Context, token, orgNam
 
 	result := make([]Repository, len(repos))
 	for i, r := range repos {
-		result[i] = Repository{
-			ID:            r.ID,
-			Name:          r.Name,
-			FullName:      r.FullName,
-			Description:   r.Description,
-			Private:       r.Private,
-			HTMLURL:       r.HTMLURL,
-			DefaultBranch: r.DefaultBranch,
-		}
+		result[i] = toRepository(r)
+	}
+	return result, nil
+}
+
+// GetOrganizationRepositoriesPage retrieves a single page of organization repositories
+func (s *Service) GetOrganizationRepositoriesPage(ctx context.Context, token, orgName string, req github.PageRequest) (*github.Page[Repository], error) {
+	page, err := s.githubOrg.GetOrganizationRepositoriesPage(ctx, token, orgName, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &github.Page[Repository]{
+		Items:      make([]Repository, len(page.Items)),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+	for i, r := range page.Items {
+		result.Items[i] = toRepository(r)
 	}
 	return result, nil
 }
@@ -70,17 +80,21 @@ func (s *Service) GetUserRepositories(ctx context.Context, token string) (map[st
 	for orgName, repos := range reposByOrg {
 		orgRepos := make([]Repository, len(repos))
 		for i, r := range repos {
-			orgRepos[i] = Repository{
-				ID:            r.ID,
-				Name:          r.Name,
-				FullName:      r.FullName,
-				Description:   r.Description,
-				Private:       r.Private,
-				HTMLURL:       r.HTMLURL,
-				DefaultBranch: r.DefaultBranch,
-			}
+			orgRepos[i] = toRepository(r)
 		}
 		result[orgName] = orgRepos
 	}
 	return result, nil
 }
+
+func toRepository(r github.Repository) Repository {
+	return Repository{
+		ID:            r.ID,
+		Name:          r.Name,
+		FullName:      r.FullName,
+		Description:   r.Description,
+		Private:       r.Private,
+		HTMLURL:       r.HTMLURL,
+		DefaultBranch: r.DefaultBranch,
+	}
+}
@@ -27,15 +27,37 @@ func (s *Service) GetBranches(ctx context.Context, token, owner, repo string) ([
 
 	result := make([]Branch, len(branches))
 	for i, b := range branches {
-		result[i] = Branch{
-			Name:      b.Name,
-			CommitSHA: b.Commit.SHA,
-			Protected: b.Protected,
-		}
+		result[i] = toBranch(b)
 	}
 	return result, nil
 }
 
+// GetBranchesPage retrieves a single page of the branches of a repository
+func (s *Service) GetBranchesPage(ctx context.Context, token, owner, repo string, req github.PageRequest) (*github.Page[Branch], error) {
+	page, err := s.githubRepo.GetBranchesPage(ctx, token, owner, repo, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &github.Page[Branch]{
+		Items:      make([]Branch, len(page.Items)),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+	for i, b := range page.Items {
+		result.Items[i] = toBranch(b)
+	}
+	return result, nil
+}
+
+func toBranch(b github.Branch) Branch {
+	return Branch{
+		Name:      b.Name,
+		CommitSHA: b.Commit.SHA,
+		Protected: b.Protected,
+	}
+}
+
 // GetCommits retrieves commits for a branch
 func (s *Service) GetCommits(ctx context.Context, token, owner, repo, branch string, perPage int) ([]interface{}, error) {
 	return s.githubRepo.GetCommits(ctx, token, owner, repo, branch, perPage)
@@ -46,6 +68,11 @@ func (s *Service) GetTags(ctx context.Context, token, owner, repo string) ([]int
 	return s.githubRepo.GetTags(ctx, token, owner, repo)
 }
 
+// GetTagsPage retrieves a single page of the tags of a repository
+func (s *Service) GetTagsPage(ctx context.Context, token, owner, repo string, req github.PageRequest) (*github.Page[interface{}], error) {
+	return s.githubRepo.GetTagsPage(ctx, token, owner, repo, req)
+}
+
 // CreateTag creates a new tag
 func (s *Service) CreateTag(ctx context.Context, token, owner, repo, tagName, commitSHA string) (*TagReference, error) {
 	ref, err := s.githubRepo.CreateTag(ctx, token, owner, repo, tagName, commitSHA)
@@ -60,9 +87,9 @@ func (s *Service) CreateTag(ctx context.Context, token, owner, repo, tagName, co
 	}, nil
 }
 
-// GetWorkflowRuns retrieves workflow runs
-func (s *Service) GetWorkflowRuns(ctx context.Context, token, owner, repo string, perPage int) ([]interface{}, error) {
-	return s.githubRepo.GetWorkflowRuns(ctx, token, owner, repo, perPage)
+// GetWorkflowRuns retrieves a single page of workflow runs
+func (s *Service) GetWorkflowRuns(ctx context.Context, token, owner, repo string, req github.PageRequest) (*github.Page[interface{}], error) {
+	return s.githubRepo.GetWorkflowRuns(ctx, token, owner, repo, req)
 }
 
 // GetWorkflowRunDetail retrieves workflow run details
@@ -0,0 +1,263 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+)
+
+// codeownersPaths are the locations GitHub reads CODEOWNERS from, in order of precedence
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// unsafePathChars matches characters replaced when a name becomes part of a file path
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// generatedNotice marks auxiliary files created by this service
+const generatedNotice = "# Generated by Calance Workflow Manager"
+
+// dockerIgnoreEntries are excluded from the Docker build context by generated .dockerignore files
+var dockerIgnoreEntries = []string{
+	".git",
+	".github",
+	".dockerignore",
+	"**/node_modules",
+	"**/__pycache__",
+	"**/*.log",
+}
+
+// AuxiliaryFiles selects repository files that are added in the same commit
+// and pull request as a new workflow
+type AuxiliaryFiles struct {
+	// Codeowners adds the workflow file to CODEOWNERS with the codeowner emails
+	Codeowners bool `json:"codeowners"`
+	// DockerIgnore adds a .dockerignore to every Docker context without one
+	DockerIgnore bool `json:"dockerIgnore"`
+	// HelmValues adds a values file stub per project and environment; Kubernetes only
+	HelmValues bool `json:"helmValues"`
+	// Dependabot adds a dependabot config for actions and Docker base images, unless one exists
+	Dependabot bool `json:"dependabot"`
+}
+
+// AuxiliaryFile is a generated file committed together with a workflow
+type AuxiliaryFile struct {
+	Path    string `json:"path"`
+	Content string `json:"-"`
+}
+
+// validateAuxiliaryFiles checks that the selected auxiliary files can be
+// generated for the deployment type of the request
+func validateAuxiliaryFiles(req *Request) error {
+	aux := req.AuxiliaryFiles
+	if aux == nil {
+		return nil
+	}
+	if aux.HelmValues && req.KubernetesCommonFields == nil {
+		return fmt.Errorf("%w: helmValues requires the kubernetes deployment type", ErrInvalidAuxiliaryFiles)
+	}
+	if aux.Codeowners && len(req.codeowners()) == 0 {
+		return fmt.Errorf("%w: codeowners requires codeowner emails", ErrInvalidAuxiliaryFiles)
+	}
+	return nil
+}
+
+// codeowners returns the codeowner emails of the request
+func (r *Request) codeowners() []string {
+	var emails string
+	switch {
+	case r.EC2CommonFields != nil:
+		emails = r.EC2CommonFields.CodeownersEmails
+	case r.KubernetesCommonFields != nil:
+		emails = r.KubernetesCommonFields.CodeownersEmailIds
+	default:
+		emails, _ = r.Config["codeownersEmails"].(string)
+	}
+	return strings.FieldsFunc(emails, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+// GenerateAuxiliaryFiles generates the auxiliary files selected by a validated
+// request. Files the repository already maintains, such as an existing
+// .dockerignore or dependabot config, are left alone; CODEOWNERS is appended to.
+func (s *Service) GenerateAuxiliaryFiles(ctx context.Context, token string, req *Request) ([]AuxiliaryFile, error) {
+	aux := req.AuxiliaryFiles
+	if aux == nil {
+		return nil, nil
+	}
+
+	// exists reports whether a file is on the default branch and returns its content
+	exists := func(filePath string) (string, bool, error) {
+		content, _, err := s.githubClient.GetFileContent(ctx, token, req.Owner, req.Repository, filePath)
+		if errors.Is(err, github.ErrNotFound) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("failed to fetch %s: %w", filePath, err)
+		}
+		return content, true, nil
+	}
+
+	var files []AuxiliaryFile
+
+	if aux.Codeowners {
+		file, err := s.codeownersFile(req, exists)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			files = append(files, *file)
+		}
+	}
+
+	if aux.DockerIgnore {
+		for _, dir := range dockerContexts(req) {
+			filePath := path.Join(dir, ".dockerignore")
+			_, found, err := exists(filePath)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				files = append(files, AuxiliaryFile{Path: filePath, Content: dockerIgnoreContent()})
+			}
+		}
+	}
+
+	if aux.HelmValues {
+		for _, file := range helmValuesStubs(req) {
+			_, found, err := exists(file.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				files = append(files, file)
+			}
+		}
+	}
+
+	if aux.Dependabot {
+		found := false
+		for _, filePath := range []string{".github/dependabot.yml", ".github/dependabot.yaml"} {
+			_, ok, err := exists(filePath)
+			if err != nil {
+				return nil, err
+			}
+			found = found || ok
+		}
+		if !found {
+			files = append(files, AuxiliaryFile{Path: ".github/dependabot.yml", Content: dependabotContent(req)})
+		}
+	}
+
+	return files, nil
+}
+
+// codeownersFile appends an entry for the workflow file to the CODEOWNERS file
+// GitHub uses, or starts .github/CODEOWNERS. It returns nil when the entry is
+// already there.
+func (s *Service) codeownersFile(req *Request, exists func(string) (string, bool, error)) (*AuxiliaryFile, error) {
+	entry := "/" + WorkflowFilePath(req.WorkflowName) + " " + strings.Join(req.codeowners(), " ")
+
+	for _, filePath := range codeownersPaths {
+		content, found, err := exists(filePath)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		for _, line := range strings.Split(content, "\n") {
+			if strings.TrimSpace(line) == entry {
+				return nil, nil
+			}
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return &AuxiliaryFile{Path: filePath, Content: content + entry + "\n"}, nil
+	}
+
+	return &AuxiliaryFile{Path: codeownersPaths[0], Content: generatedNotice + "\n" + entry + "\n"}, nil
+}
+
+func auxiliaryPaths(files []AuxiliaryFile) []string {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	return paths
+}
+
+// dockerContexts returns the distinct Docker contexts of the projects, sorted
+func dockerContexts(req *Request) []string {
+	seen := make(map[string]bool)
+	for _, project := range req.Projects {
+		seen[project.DockerContextPath] = true
+	}
+	return sortedKeys(seen)
+}
+
+func dockerIgnoreContent() string {
+	return generatedNotice + "\n" + strings.Join(dockerIgnoreEntries, "\n") + "\n"
+}
+
+// helmValuesStubs returns a values file per Kubernetes project and environment,
+// named after the Helm release the deploy job installs
+func helmValuesStubs(req *Request) []AuxiliaryFile {
+	var files []AuxiliaryFile
+	for _, project := range req.KubernetesProjects {
+		release := req.Repository + "-" + project.Name
+		dir := path.Join("deploy", "helm", unsafePathChars.ReplaceAllString(release, "-"))
+		for _, env := range req.Stages() {
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s\n", generatedNotice)
+			fmt.Fprintf(&b, "# Values stub for the %s release in %s. Review it and move it to\n", strconv.Quote(release), env.Name)
+			fmt.Fprintf(&b, "# %s, which the deploy job reads values from.\n", strconv.Quote(req.KubernetesCommonFields.HelmValuesRepository))
+			b.WriteString("image:\n")
+			fmt.Fprintf(&b, "  repository: %s\n", strconv.Quote(req.Owner+"/"+release))
+			b.WriteString("  # Set by the deploy job from the pushed tag\n")
+			b.WriteString("  tag: \"\"\n")
+			b.WriteString("replicaCount: 1\n")
+			b.WriteString("resources: {}\n")
+
+			files = append(files, AuxiliaryFile{
+				Path:    path.Join(dir, fmt.Sprintf("values-%s.yaml", env.Name)),
+				Content: b.String(),
+			})
+		}
+	}
+	return files
+}
+
+// dependabotContent configures weekly updates of the actions and of the base
+// images of every Dockerfile directory. The shared workflows are left to
+// release tag upgrades, which also update the embedded config.
+func dependabotContent(req *Request) string {
+	dirs := make(map[string]bool)
+	for _, project := range req.Projects {
+		dir := path.Dir(project.DockerfilePath)
+		if dir == "." {
+			dir = ""
+		}
+		dirs["/"+dir] = true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nversion: 2\nupdates:\n", generatedNotice)
+	b.WriteString("  - package-ecosystem: \"github-actions\"\n")
+	b.WriteString("    directory: \"/\"\n")
+	b.WriteString("    schedule:\n      interval: \"weekly\"\n")
+	b.WriteString("    ignore:\n      - dependency-name: \"Calance-US/calance-workflows*\"\n")
+
+	for _, dir := range sortedKeys(dirs) {
+		b.WriteString("  - package-ecosystem: \"docker\"\n")
+		fmt.Fprintf(&b, "    directory: %s\n", strconv.Quote(dir))
+		b.WriteString("    schedule:\n      interval: \"weekly\"\n")
+	}
+	return b.String()
+}
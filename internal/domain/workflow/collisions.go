@@ -0,0 +1,182 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConflictMode selects what creating a workflow does when one of the same
+// name already exists
+type ConflictMode string
+
+const (
+	// ConflictFail rejects the request
+	ConflictFail ConflictMode = "fail"
+	// ConflictUpdate replaces the existing file through a new pull request
+	ConflictUpdate ConflictMode = "update"
+	// ConflictSuffix creates the workflow under the first free name <name>-2, <name>-3, ...
+	ConflictSuffix ConflictMode = "suffix"
+)
+
+// maxNameSuffix bounds the names tried by ConflictSuffix
+const maxNameSuffix = 20
+
+// Collision describes an existing workflow file or pending pull request for a workflow name
+type Collision struct {
+	WorkflowName string              `json:"workflowName"`
+	FilePath     string              `json:"filePath,omitempty"`
+	SHA          string              `json:"sha,omitempty"`
+	PullRequests []PendingWorkflowPR `json:"pullRequests,omitempty"`
+}
+
+// PendingWorkflowPR is an open pull request that adds a workflow
+type PendingWorkflowPR struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Branch string `json:"branch"`
+}
+
+// validateConflictMode checks the onConflict mode of a request
+func validateConflictMode(mode ConflictMode) error {
+	switch mode {
+	case "", ConflictFail, ConflictUpdate, ConflictSuffix:
+		return nil
+	}
+	return fmt.Errorf("%w: '%s'", ErrInvalidConflictMode, mode)
+}
+
+// existingWorkflows are the workflow files on the default branch and the open
+// pull requests of a repository, fetched once to check several names
+type existingWorkflows struct {
+	// files maps workflow file paths to their blob SHA
+	files map[string]string
+	// pending maps workflow names to the open pull requests adding them
+	pending map[string][]PendingWorkflowPR
+}
+
+// loadExistingWorkflows lists the workflow files and open pull requests of a repository
+func (s *Service) loadExistingWorkflows(ctx context.Context, token, owner, repo string) (*existingWorkflows, error) {
+	files, err := s.githubClient.GetWorkflowFiles(ctx, token, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing workflow file: %w", err)
+	}
+	pulls, err := s.githubClient.ListOpenPullRequests(ctx, token, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open pull requests: %w", err)
+	}
+
+	existing := &existingWorkflows{
+		files:   make(map[string]string, len(files)),
+		pending: make(map[string][]PendingWorkflowPR),
+	}
+	for _, file := range files {
+		existing.files[file.Path] = file.SHA
+	}
+	for _, pr := range pulls {
+		name, ok := workflowBranchName(pr.Head.Ref)
+		if !ok {
+			continue
+		}
+		existing.pending[name] = append(existing.pending[name], PendingWorkflowPR{
+			Number: pr.Number,
+			URL:    pr.HTMLURL,
+			Branch: pr.Head.Ref,
+		})
+	}
+	return existing, nil
+}
+
+// collision returns the workflow file and open pull requests for a workflow
+// name, or nil when the name is free
+func (e *existingWorkflows) collision(workflowName string) *Collision {
+	collision := &Collision{
+		WorkflowName: workflowName,
+		PullRequests: e.pending[workflowName],
+	}
+	for _, filePath := range []string{WorkflowFilePath(workflowName), ".github/workflows/" + workflowName + ".yaml"} {
+		if sha, ok := e.files[filePath]; ok {
+			collision.FilePath = filePath
+			collision.SHA = sha
+			break
+		}
+	}
+
+	if collision.FilePath == "" && len(collision.PullRequests) == 0 {
+		return nil
+	}
+	return collision
+}
+
+// FindCollision looks for a workflow file with the given name on the default
+// branch and for open pull requests from branches created for it. It returns
+// nil when the name is free.
+func (s *Service) FindCollision(ctx context.Context, token, owner, repo, workflowName string) (*Collision, error) {
+	existing, err := s.loadExistingWorkflows(ctx, token, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return existing.collision(workflowName), nil
+}
+
+// workflowBranchName returns the workflow name of a branch created by
+// CreateWorkflow, that is workflow/<name>-<timestamp>
+func workflowBranchName(branch string) (string, bool) {
+	rest, ok := strings.CutPrefix(branch, "workflow/")
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndex(rest, "-")
+	if i <= 0 {
+		return "", false
+	}
+	if _, err := strconv.ParseInt(rest[i+1:], 10, 64); err != nil {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// ResolveCollision applies the request's onConflict mode before a workflow is
+// created. With ConflictSuffix the request is renamed to a free name; with
+// ConflictUpdate the existing file to replace is returned. A nil collision
+// means the workflow can be created as requested.
+func (s *Service) ResolveCollision(ctx context.Context, token string, req *Request) (*Collision, error) {
+	existing, err := s.loadExistingWorkflows(ctx, token, req.Owner, req.Repository)
+	if err != nil {
+		return nil, err
+	}
+	collision := existing.collision(req.WorkflowName)
+	if collision == nil {
+		return nil, nil
+	}
+
+	switch req.OnConflict {
+	case ConflictUpdate:
+		if collision.FilePath == "" {
+			return collision, fmt.Errorf("%w: pull request #%d already adds workflow '%s'",
+				ErrWorkflowAlreadyExists, collision.PullRequests[0].Number, req.WorkflowName)
+		}
+		return collision, nil
+
+	case ConflictSuffix:
+		for i := 2; i <= maxNameSuffix; i++ {
+			candidate := fmt.Sprintf("%s-%d", req.WorkflowName, i)
+			if !isValidWorkflowName(candidate) {
+				break
+			}
+			if existing.collision(candidate) == nil {
+				req.WorkflowName = candidate
+				return nil, nil
+			}
+		}
+		return collision, fmt.Errorf("%w: no free name found for '%s'", ErrWorkflowAlreadyExists, req.WorkflowName)
+
+	default:
+		if collision.FilePath != "" {
+			return collision, fmt.Errorf("%w: %s exists on the default branch", ErrWorkflowAlreadyExists, collision.FilePath)
+		}
+		return collision, fmt.Errorf("%w: pull request #%d already adds workflow '%s'",
+			ErrWorkflowAlreadyExists, collision.PullRequests[0].Number, req.WorkflowName)
+	}
+}
@@ -0,0 +1,300 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+)
+
+const (
+	// configHeaderPrefix starts the comment line that embeds the generation
+	// request in a workflow file
+	configHeaderPrefix = "# calance-workflow-config: "
+	configHeaderNotice = "# Generated by Calance Workflow Manager. Regenerate it from its config instead of editing by hand."
+
+	// configVersion is bumped when the embedded request changes incompatibly
+	configVersion = 1
+)
+
+// Config sources
+const (
+	ConfigSourceFile    = "file"
+	ConfigSourceHistory = "history"
+)
+
+// embeddedConfig is the JSON document stored in the config header
+type embeddedConfig struct {
+	Version         int    `json:"version"`
+	TemplateVersion string `json:"templateVersion,omitempty"`
+	// Checksum is the SHA-256 of the YAML following the header when it was
+	// written, so manual changes to the file can be told apart
+	Checksum string   `json:"checksum,omitempty"`
+	Request  *Request `json:"request"`
+}
+
+// WorkflowConfig is the request a workflow was generated from
+type WorkflowConfig struct {
+	Owner        string   `json:"owner"`
+	Repository   string   `json:"repository"`
+	WorkflowName string   `json:"workflowName"`
+	FilePath     string   `json:"filePath,omitempty"`
+	SHA          string   `json:"sha,omitempty"`
+	Source       string   `json:"source"`
+	Request      *Request `json:"request"`
+}
+
+// RegenerateRequest represents changes to the stored request of a workflow.
+// Changes is a JSON merge patch (RFC 7386) applied to the stored request.
+type RegenerateRequest struct {
+	WorkflowName  string          `json:"workflowName" binding:"required"`
+	Changes       json.RawMessage `json:"changes"`
+	CommitMessage string          `json:"commitMessage"`
+}
+
+// embedConfigHeader prepends the redacted request to generated YAML as a comment
+func embedConfigHeader(req *Request, templateVersion, yamlContent string) (string, error) {
+	header, err := configHeader(&embeddedConfig{
+		TemplateVersion: templateVersion,
+		Checksum:        contentChecksum(yamlContent),
+		Request:         req,
+	})
+	if err != nil {
+		return "", err
+	}
+	return configHeaderNotice + "\n" + header + "\n" + yamlContent, nil
+}
+
+// configHeader returns the header line embedding config
+func configHeader(config *embeddedConfig) (string, error) {
+	header := *config
+	header.Version = configVersion
+	header.Request = config.Request.Redacted()
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode workflow config: %w", err)
+	}
+	return configHeaderPrefix + string(data), nil
+}
+
+// contentChecksum returns the hex SHA-256 of YAML content
+func contentChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// readConfigHeader finds the config header among the leading comments of a
+// generated workflow file. It returns the embedded config and the YAML body
+// that follows the header.
+func readConfigHeader(yamlContent string) (*embeddedConfig, string, error) {
+	marker := strings.TrimSpace(configHeaderPrefix)
+	lines := strings.Split(yamlContent, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if !strings.HasPrefix(line, marker) {
+			continue
+		}
+
+		var config embeddedConfig
+		payload := strings.TrimSpace(strings.TrimPrefix(line, marker))
+		if err := json.Unmarshal([]byte(payload), &config); err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidWorkflowConfig, err)
+		}
+		if config.Version != configVersion || config.Request == nil {
+			return nil, "", fmt.Errorf("%w: unsupported version %d", ErrInvalidWorkflowConfig, config.Version)
+		}
+		return &config, strings.Join(lines[i+1:], "\n"), nil
+	}
+	return nil, "", ErrWorkflowConfigNotFound
+}
+
+// rewriteConfigHeader lets update change the embedded config of a generated
+// workflow, given the YAML body that follows the header, and rewrites the header
+func rewriteConfigHeader(yamlContent string, update func(config *embeddedConfig, body string)) (string, error) {
+	config, body, err := readConfigHeader(yamlContent)
+	if err != nil {
+		return "", err
+	}
+	update(config, body)
+
+	header, err := configHeader(config)
+	if err != nil {
+		return "", err
+	}
+
+	marker := strings.TrimSpace(configHeaderPrefix)
+	lines := strings.Split(yamlContent, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), marker) {
+			lines[i] = header
+			break
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ParseConfigHeader returns the request embedded in the leading comments of a
+// generated workflow file
+func ParseConfigHeader(yamlContent string) (*Request, error) {
+	config, _, err := readConfigHeader(yamlContent)
+	if err != nil {
+		return nil, err
+	}
+	return config.Request, nil
+}
+
+// ApplyConfigChanges applies a JSON merge patch to a stored request. The
+// owner, repository and workflow name identify the workflow and cannot change.
+func ApplyConfigChanges(base *Request, changes json.RawMessage) (*Request, error) {
+	if len(changes) == 0 || string(changes) == "null" {
+		merged := *base
+		return &merged, nil
+	}
+
+	var patch interface{}
+	if err := json.Unmarshal(changes, &patch); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfigChanges, err)
+	}
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%w: changes must be a JSON object", ErrInvalidConfigChanges)
+	}
+
+	data, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return nil, err
+	}
+	var merged Request
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfigChanges, err)
+	}
+
+	if merged.Owner != base.Owner || merged.Repository != base.Repository || merged.WorkflowName != base.WorkflowName {
+		return nil, fmt.Errorf("%w: owner, repository and workflowName cannot be changed", ErrInvalidConfigChanges)
+	}
+	return &merged, nil
+}
+
+// mergePatch implements RFC 7386: objects are merged recursively, null removes
+// a member and any other value, arrays included, replaces the target
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// GetWorkflowConfig reads the request embedded in a workflow file on the
+// default branch. The returned config has no FilePath when the file does not
+// exist and no Request when the file has no config header. Since GitHub also
+// answers 404 for private repositories the token cannot read, the repository
+// is checked first and ErrRepositoryNotFound returned if it is not accessible.
+func (s *Service) GetWorkflowConfig(ctx context.Context, token, owner, repo, workflowName string) (*WorkflowConfig, error) {
+	if !isValidWorkflowName(workflowName) {
+		return nil, ErrInvalidWorkflowName
+	}
+
+	if err := s.githubClient.VerifyRepository(ctx, token, owner, repo); err != nil {
+		if errors.Is(err, github.ErrNotFound) || errors.Is(err, github.ErrForbidden) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrRepositoryNotFound, owner, repo)
+		}
+		return nil, fmt.Errorf("failed to verify repository: %w", err)
+	}
+
+	config := &WorkflowConfig{
+		Owner:        owner,
+		Repository:   repo,
+		WorkflowName: workflowName,
+	}
+	for _, ext := range []string{".yml", ".yaml"} {
+		filePath := ".github/workflows/" + workflowName + ext
+		content, sha, err := s.githubClient.GetFileContent(ctx, token, owner, repo, filePath)
+		if errors.Is(err, github.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch workflow file: %w", err)
+		}
+
+		config.FilePath = filePath
+		config.SHA = sha
+
+		req, err := ParseConfigHeader(content)
+		if errors.Is(err, ErrWorkflowConfigNotFound) {
+			return config, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		config.Source = ConfigSourceFile
+		config.Request = req
+		return config, nil
+	}
+	return config, nil
+}
+
+// CheckFile returns ErrWorkflowFileNotFound unless the workflow file was read
+// from the default branch, which regenerating it requires
+func (c *WorkflowConfig) CheckFile() error {
+	if c.FilePath == "" || c.SHA == "" {
+		return ErrWorkflowFileNotFound
+	}
+	return nil
+}
+
+// RegenerateWorkflow opens a pull request replacing a workflow file with YAML
+// generated from an updated request
+func (s *Service) RegenerateWorkflow(ctx context.Context, token string, config *WorkflowConfig, request *Request, yamlContent, commitMessage string) (*Response, error) {
+	if err := config.CheckFile(); err != nil {
+		return nil, err
+	}
+
+	if commitMessage == "" {
+		commitMessage = fmt.Sprintf("Regenerate workflow: %s", config.WorkflowName)
+	}
+
+	return s.UpdateWorkflow(ctx, token, &UpdateWorkflowRequest{
+		Owner:         config.Owner,
+		Repository:    config.Repository,
+		FilePath:      config.FilePath,
+		Content:       yamlContent,
+		SHA:           config.SHA,
+		CommitMessage: commitMessage,
+		PullRequest:   request.PullRequest,
+		DirectCommit:  request.DirectCommit,
+		Request:       request,
+	})
+}
@@ -0,0 +1,69 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/template"
+)
+
+// newTestService returns a service whose GitHub clients call handler
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	github.SetBaseURL(server.URL)
+	t.Cleanup(func() { github.SetBaseURL("") })
+
+	return NewService(template.NewStore(template.NewEmbeddedSource()), nil)
+}
+
+func TestGetWorkflowConfigRejectsInaccessibleRepository(t *testing.T) {
+	var fileRequests atomic.Int32
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/contents/") {
+			fileRequests.Add(1)
+		}
+		// GitHub answers 404 for private repositories the token cannot read
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	})
+
+	config, err := service.GetWorkflowConfig(context.Background(), "token", "acme", "private", "deploy")
+	if !errors.Is(err, ErrRepositoryNotFound) {
+		t.Fatalf("err = %v, want ErrRepositoryNotFound", err)
+	}
+	if config != nil {
+		t.Errorf("config = %+v, want nil", config)
+	}
+	if got := fileRequests.Load(); got != 0 {
+		t.Errorf("workflow file requested %d times, want 0", got)
+	}
+}
+
+func TestGetWorkflowConfigWithoutWorkflowFile(t *testing.T) {
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/acme/app" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name":"app","full_name":"acme/app","default_branch":"main"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	})
+
+	config, err := service.GetWorkflowConfig(context.Background(), "token", "acme", "app", "deploy")
+	if err != nil {
+		t.Fatalf("GetWorkflowConfig: %v", err)
+	}
+	if config.FilePath != "" || config.Request != nil {
+		t.Errorf("config = %+v, want no file and no request", config)
+	}
+}
@@ -0,0 +1,176 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/diff"
+)
+
+// Input change kinds
+const (
+	InputAdded   = "added"
+	InputRemoved = "removed"
+	InputChanged = "changed"
+)
+
+// DiffRequest represents workflow content to compare with the file in the repository
+type DiffRequest struct {
+	FilePath string `json:"filePath" binding:"required"`
+	Content  string `json:"content" binding:"required"`
+}
+
+// WorkflowDiff compares new workflow content with the file on the default branch
+type WorkflowDiff struct {
+	FilePath string `json:"filePath"`
+	// Exists is false when the file is not in the repository yet; the diff then adds every line
+	Exists    bool       `json:"exists"`
+	SHA       string     `json:"sha,omitempty"`
+	Identical bool       `json:"identical"`
+	Unified   string     `json:"unified"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	Jobs      JobChanges `json:"jobs"`
+}
+
+// JobChanges summarizes a workflow change by job
+type JobChanges struct {
+	Added   []string    `json:"added"`
+	Removed []string    `json:"removed"`
+	Changed []JobChange `json:"changed"`
+}
+
+// JobChange describes a job present in both versions of a workflow
+type JobChange struct {
+	Job    string        `json:"job"`
+	Inputs []InputChange `json:"inputs"`
+	// OtherChanges is set when properties besides the with: inputs changed
+	OtherChanges bool `json:"otherChanges"`
+}
+
+// InputChange is a changed with: input of a job
+type InputChange struct {
+	Name   string      `json:"name"`
+	Change string      `json:"change"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+}
+
+// DiffWorkflowFile compares content with a workflow file on the default branch
+func (s *Service) DiffWorkflowFile(ctx context.Context, token, owner, repo, filePath, content string) (*WorkflowDiff, error) {
+	if err := validateWorkflowPath(filePath); err != nil {
+		return nil, err
+	}
+
+	current, sha, err := s.githubClient.GetFileContent(ctx, token, owner, repo, filePath)
+	if err != nil && !errors.Is(err, github.ErrNotFound) {
+		return nil, fmt.Errorf("failed to fetch file content: %w", err)
+	}
+
+	result := DiffWorkflows(filePath, current, content)
+	result.Exists = err == nil
+	result.SHA = sha
+	return result, nil
+}
+
+// DiffWorkflows compares two versions of a workflow file line by line and by job
+func DiffWorkflows(filePath, oldContent, newContent string) *WorkflowDiff {
+	lines := diff.Unified("a/"+filePath, "b/"+filePath, oldContent, newContent)
+	return &WorkflowDiff{
+		FilePath:  filePath,
+		Identical: oldContent == newContent,
+		Unified:   lines.Unified,
+		Additions: lines.Additions,
+		Deletions: lines.Deletions,
+		Jobs:      diffJobs(parseJobs(oldContent), parseJobs(newContent)),
+	}
+}
+
+// parseJobs returns the jobs of a workflow by ID. Content that does not parse has no jobs.
+func parseJobs(content string) map[string]map[string]interface{} {
+	var document struct {
+		Jobs map[string]map[string]interface{} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil
+	}
+	return document.Jobs
+}
+
+// diffJobs reports added and removed jobs and the with: inputs of changed jobs
+func diffJobs(oldJobs, newJobs map[string]map[string]interface{}) JobChanges {
+	changes := JobChanges{Added: []string{}, Removed: []string{}, Changed: []JobChange{}}
+
+	for _, id := range sortedKeys(newJobs) {
+		if _, ok := oldJobs[id]; !ok {
+			changes.Added = append(changes.Added, id)
+		}
+	}
+
+	for _, id := range sortedKeys(oldJobs) {
+		newJob, ok := newJobs[id]
+		if !ok {
+			changes.Removed = append(changes.Removed, id)
+			continue
+		}
+
+		oldJob := oldJobs[id]
+		change := JobChange{Job: id, Inputs: diffInputs(oldJob["with"], newJob["with"])}
+		for _, key := range sortedKeys(mergeKeys(oldJob, newJob)) {
+			if key != "with" && !reflect.DeepEqual(oldJob[key], newJob[key]) {
+				change.OtherChanges = true
+				break
+			}
+		}
+		if len(change.Inputs) > 0 || change.OtherChanges {
+			changes.Changed = append(changes.Changed, change)
+		}
+	}
+	return changes
+}
+
+// diffInputs compares the with: mappings of two versions of a job
+func diffInputs(oldWith, newWith interface{}) []InputChange {
+	oldInputs, _ := oldWith.(map[string]interface{})
+	newInputs, _ := newWith.(map[string]interface{})
+
+	inputs := []InputChange{}
+	for _, name := range sortedKeys(mergeKeys(oldInputs, newInputs)) {
+		oldValue, inOld := oldInputs[name]
+		newValue, inNew := newInputs[name]
+		switch {
+		case !inOld:
+			inputs = append(inputs, InputChange{Name: name, Change: InputAdded, New: newValue})
+		case !inNew:
+			inputs = append(inputs, InputChange{Name: name, Change: InputRemoved, Old: oldValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			inputs = append(inputs, InputChange{Name: name, Change: InputChanged, Old: oldValue, New: newValue})
+		}
+	}
+	return inputs
+}
+
+func mergeKeys[V any](a, b map[string]V) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	return keys
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+)
+
+// DirectCommitOptions commits a workflow change straight to a branch instead
+// of opening a pull request
+type DirectCommitOptions struct {
+	// Branch receives the commit; the default branch when empty
+	Branch string `json:"branch,omitempty"`
+}
+
+// DirectCommitPolicy lists the repositories whose workflows may be changed
+// without a pull request, as owner/repository patterns such as "acme/sandbox-*"
+// or "playground/*". Matching is case-insensitive. A nil policy allows none.
+type DirectCommitPolicy struct {
+	patterns []string
+}
+
+// NewDirectCommitPolicy creates a policy from owner/repository patterns in path.Match syntax
+func NewDirectCommitPolicy(patterns []string) (*DirectCommitPolicy, error) {
+	policy := &DirectCommitPolicy{}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if strings.Count(pattern, "/") != 1 {
+			return nil, fmt.Errorf("direct commit pattern '%s' must have the form owner/repository", pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("direct commit pattern '%s': %w", pattern, err)
+		}
+		policy.patterns = append(policy.patterns, pattern)
+	}
+	return policy, nil
+}
+
+// Allows reports whether workflows of a repository may be committed without a pull request
+func (p *DirectCommitPolicy) Allows(owner, repo string) bool {
+	if p == nil {
+		return false
+	}
+	name := strings.ToLower(owner + "/" + repo)
+	for _, pattern := range p.patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDirectCommit checks the direct commit options of a request
+func validateDirectCommit(opts *DirectCommitOptions, pullRequest *PullRequestOptions) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.Branch != "" && !isValidReleaseTag(opts.Branch) {
+		return fmt.Errorf("%w: branch '%s' is not a valid branch name", ErrInvalidDirectCommit, opts.Branch)
+	}
+	if pullRequest != nil {
+		return fmt.Errorf("%w: pullRequest options cannot be combined with directCommit", ErrInvalidDirectCommit)
+	}
+	return nil
+}
+
+// CheckDirectCommit checks that a direct commit to a repository would be
+// accepted, so requests can be rejected before anything is written
+func (s *Service) CheckDirectCommit(ctx context.Context, token, owner, repo string, opts *DirectCommitOptions) error {
+	if opts == nil {
+		return nil
+	}
+	defaultBranch, err := s.githubClient.GetDefaultBranch(ctx, token, owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to get default branch: %w", err)
+	}
+	_, err = s.directCommitBranch(ctx, token, owner, repo, opts, defaultBranch)
+	return err
+}
+
+// directCommitBranch returns the branch a direct commit goes to, after
+// checking the policy and that the branch is not protected
+func (s *Service) directCommitBranch(ctx context.Context, token, owner, repo string, opts *DirectCommitOptions, defaultBranch string) (string, error) {
+	if !s.directCommits.Allows(owner, repo) {
+		return "", fmt.Errorf("%w for %s/%s", ErrDirectCommitNotAllowed, owner, repo)
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		branch = defaultBranch
+	}
+
+	protected, err := s.githubClient.IsBranchProtected(ctx, token, owner, repo, branch)
+	if errors.Is(err, github.ErrNotFound) {
+		return "", fmt.Errorf("%w: branch '%s' does not exist", ErrInvalidDirectCommit, branch)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check branch protection: %w", err)
+	}
+	if protected {
+		return "", fmt.Errorf("%w: '%s'", ErrBranchProtected, branch)
+	}
+	return branch, nil
+}
+
+// commitWorkflow writes a new workflow and its auxiliary files in a single
+// commit on top of the branch chosen by the direct commit options of req
+func (s *Service) commitWorkflow(ctx context.Context, token string, req *Request, content string, auxiliary []AuxiliaryFile, defaultBranch string) (*Response, error) {
+	branch, err := s.directCommitBranch(ctx, token, req.Owner, req.Repository, req.DirectCommit, defaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	headSHA, err := s.githubClient.GetBranchSHA(ctx, token, req.Owner, req.Repository, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch SHA: %w", err)
+	}
+
+	filePath := WorkflowFilePath(req.WorkflowName)
+	commit := s.githubClient.NewCommit(req.Owner, req.Repository).AddFile(filePath, content)
+	for _, file := range auxiliary {
+		commit.AddFile(file.Path, file.Content)
+	}
+	commitSHA, err := commit.Create(ctx, token, headSHA, fmt.Sprintf("Add workflow: %s", req.WorkflowName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if err := s.githubClient.UpdateBranch(ctx, token, req.Owner, req.Repository, branch, commitSHA); err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Owner:        req.Owner,
+		Repository:   req.Repository,
+		WorkflowName: req.WorkflowName,
+		FilePath:     filePath,
+		Branch:       branch,
+		CommitSHA:    commitSHA,
+		Files:        commit.Files(),
+		Message:      fmt.Sprintf("Workflow '%s' committed to branch '%s'", req.WorkflowName, branch),
+	}, nil
+}
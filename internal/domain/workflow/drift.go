@@ -0,0 +1,199 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// Drift statuses of a generated workflow
+const (
+	DriftStatusInSync  = "in_sync"
+	DriftStatusDrifted = "drifted"
+	DriftStatusMissing = "missing"
+	// DriftStatusUnknown marks generated workflows without a stored request to compare with
+	DriftStatusUnknown = "unknown"
+	DriftStatusError   = "error"
+)
+
+// StoredRequest is the latest recorded request of a workflow
+type StoredRequest struct {
+	Repository   string
+	WorkflowName string
+	Request      *Request
+}
+
+// DriftReport compares the generated workflows of an organization with the
+// requests they were generated from
+type DriftReport struct {
+	Organization        string          `json:"organization"`
+	TemplateVersion     string          `json:"templateVersion"`
+	RepositoriesScanned int             `json:"repositoriesScanned"`
+	Workflows           []WorkflowDrift `json:"workflows"`
+	CheckedAt           time.Time       `json:"checkedAt"`
+}
+
+// WorkflowDrift is the drift of one generated workflow
+type WorkflowDrift struct {
+	Repository   string `json:"repository"`
+	WorkflowName string `json:"workflowName"`
+	FilePath     string `json:"filePath,omitempty"`
+	Status       string `json:"status"`
+	// ManualChanges is set when the committed file differs from what was
+	// generated. Files without a checksum are compared with a regeneration
+	// instead, so template changes are reported as manual changes too.
+	ManualChanges bool `json:"manualChanges"`
+	// OutdatedTemplate is set when regenerating with the current templates
+	// changes the workflow
+	OutdatedTemplate bool   `json:"outdatedTemplate"`
+	TemplateVersion  string `json:"templateVersion,omitempty"`
+	ConfigSource     string `json:"configSource,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// DetectDrift regenerates every generated workflow in the repositories of an
+// organization and compares the result with the file on the default branch.
+// Requests are read from the workflow files and, for files without a config
+// header, from stored; stored requests whose file no longer exists are
+// reported as missing. A non-empty repositories limits the scan.
+func (s *Service) DetectDrift(ctx context.Context, token, org string, repositories []string, stored []StoredRequest) (*DriftReport, error) {
+	repos, err := s.orgClient.GetOrganizationRepositories(ctx, token, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	storedByRepo := make(map[string][]StoredRequest)
+	for _, request := range stored {
+		storedByRepo[request.Repository] = append(storedByRepo[request.Repository], request)
+	}
+
+	report := &DriftReport{
+		Organization:    org,
+		TemplateVersion: s.templateStore.Version(),
+		Workflows:       []WorkflowDrift{},
+		CheckedAt:       time.Now(),
+	}
+	for _, repo := range repos {
+		if repo.Archived || (len(repositories) > 0 && !containsString(repositories, repo.Name)) {
+			continue
+		}
+		report.RepositoriesScanned++
+
+		drifts, err := s.detectRepositoryDrift(ctx, token, org, repo.Name, storedByRepo[repo.Name])
+		if err != nil {
+			logger.Error().Err(err).Str("owner", org).Str("repo", repo.Name).Msg("Failed to detect workflow drift")
+			report.Workflows = append(report.Workflows, WorkflowDrift{
+				Repository: repo.Name,
+				Status:     DriftStatusError,
+				Error:      err.Error(),
+			})
+			continue
+		}
+		report.Workflows = append(report.Workflows, drifts...)
+	}
+	return report, nil
+}
+
+// detectRepositoryDrift reports the drift of the generated workflows of one repository
+func (s *Service) detectRepositoryDrift(ctx context.Context, token, owner, repo string, stored []StoredRequest) ([]WorkflowDrift, error) {
+	files, err := s.githubClient.GetWorkflowFiles(ctx, token, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	storedByName := make(map[string]*Request, len(stored))
+	for _, request := range stored {
+		storedByName[request.WorkflowName] = request.Request
+	}
+
+	var drifts []WorkflowDrift
+	for _, file := range files {
+		content, _, err := s.githubClient.GetFileContent(ctx, token, owner, repo, file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", file.Path, err)
+		}
+
+		name := WorkflowNameFromPath(file.Path)
+		storedRequest, hasStored := storedByName[name]
+		delete(storedByName, name)
+		if !isGeneratedWorkflow(content) && !hasStored {
+			continue
+		}
+
+		drift := WorkflowDrift{Repository: repo, WorkflowName: name, FilePath: file.Path}
+		s.compareWorkflow(&drift, content, storedRequest)
+		drifts = append(drifts, drift)
+	}
+
+	for _, request := range stored {
+		if _, missing := storedByName[request.WorkflowName]; missing {
+			drifts = append(drifts, WorkflowDrift{
+				Repository:   repo,
+				WorkflowName: request.WorkflowName,
+				Status:       DriftStatusMissing,
+				ConfigSource: ConfigSourceHistory,
+			})
+		}
+	}
+	return drifts, nil
+}
+
+// compareWorkflow fills in the drift of a committed workflow file. The
+// request embedded in the file takes precedence over the stored one.
+func (s *Service) compareWorkflow(drift *WorkflowDrift, content string, stored *Request) {
+	var checksum string
+	committed := content
+	request := stored
+
+	config, body, err := readConfigHeader(content)
+	switch {
+	case err == nil:
+		request = config.Request
+		checksum = config.Checksum
+		committed = body
+		drift.TemplateVersion = config.TemplateVersion
+		drift.ConfigSource = ConfigSourceFile
+	case request != nil:
+		drift.ConfigSource = ConfigSourceHistory
+	default:
+		drift.Status = DriftStatusUnknown
+		if !errors.Is(err, ErrWorkflowConfigNotFound) {
+			drift.Status = DriftStatusError
+			drift.Error = err.Error()
+		}
+		return
+	}
+
+	regenerated, err := s.GenerateWorkflow(request)
+	if err != nil {
+		drift.Status = DriftStatusError
+		drift.Error = err.Error()
+		return
+	}
+	_, regeneratedBody, err := readConfigHeader(regenerated)
+	if err != nil {
+		drift.Status = DriftStatusError
+		drift.Error = err.Error()
+		return
+	}
+
+	if checksum != "" {
+		drift.ManualChanges = contentChecksum(committed) != checksum
+		if drift.ManualChanges {
+			// The generated content is unknown, so only the template version tells
+			drift.OutdatedTemplate = drift.TemplateVersion != s.templateStore.Version()
+		} else {
+			drift.OutdatedTemplate = regeneratedBody != committed
+		}
+	} else {
+		drift.ManualChanges = regeneratedBody != committed
+	}
+
+	drift.Status = DriftStatusInSync
+	if drift.ManualChanges || drift.OutdatedTemplate {
+		drift.Status = DriftStatusDrifted
+	}
+}
@@ -0,0 +1,49 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/filterpattern"
+)
+
+// environmentNamePattern keeps environment names usable in job IDs and input names
+var environmentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+// validateEnvironments checks the deployment stages of a request and the
+// dotenv files projects define for them
+func validateEnvironments(req *Request) error {
+	names := make(map[string]bool)
+	patterns := make(map[string]string)
+
+	for _, env := range req.Stages() {
+		if !environmentNamePattern.MatchString(env.Name) {
+			return fmt.Errorf("%w: name '%s' must start with a lowercase letter and contain only lowercase letters, digits, '-' and '_'", ErrInvalidEnvironment, env.Name)
+		}
+		if names[env.Name] {
+			return fmt.Errorf("%w: '%s' is defined more than once", ErrInvalidEnvironment, env.Name)
+		}
+		names[env.Name] = true
+
+		pattern, err := filterpattern.Parse(env.TagPattern)
+		if err != nil {
+			return fmt.Errorf("%w: '%s' tag pattern: %v", ErrInvalidEnvironment, env.Name, err)
+		}
+		if pattern.Negated {
+			return fmt.Errorf("%w: '%s' tag pattern must not be negated", ErrInvalidEnvironment, env.Name)
+		}
+		if other, exists := patterns[env.TagPattern]; exists {
+			return fmt.Errorf("%w: '%s' uses the same tag pattern as '%s'", ErrInvalidEnvironment, env.Name, other)
+		}
+		patterns[env.TagPattern] = env.Name
+	}
+
+	for _, project := range req.Projects {
+		for name := range project.DotEnv {
+			if !names[name] {
+				return fmt.Errorf("%w: project '%s' has a dotenv file for unknown environment '%s'", ErrInvalidEnvironment, project.Name, name)
+			}
+		}
+	}
+	return nil
+}
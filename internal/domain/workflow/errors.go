@@ -1,6 +1,10 @@
 package workflow
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+)
 
 var (
 	// Validation errors
@@ -9,9 +13,41 @@ var (
 	ErrKubernetesCommonFieldsRequired = errors.New("kubernetesCommonFields is required for Kubernetes deployment type")
 	ErrKubernetesProjectsRequired     = errors.New("kubernetesProjects is required for Kubernetes deployment type")
 	ErrInvalidWorkflowName            = errors.New("workflow name must contain only alphanumeric characters, hyphens, and underscores")
-	ErrInvalidDeploymentType          = errors.New("deployment type must be either 'ec2' or 'kubernetes'")
+	ErrInvalidReleaseTag              = errors.New("release tag must be a valid git ref name")
+	ErrInvalidDeploymentType          = errors.New("unsupported deployment type")
+	ErrConfigFieldRequired            = errors.New("config field is required for this deployment type")
+	ErrConfigFieldInvalid             = errors.New("config field has an invalid type")
+	ErrInvalidProjectPath             = errors.New("invalid project path")
+	ErrProjectPathNotFound            = errors.New("project path not found in repository")
+	ErrInvalidEnvironment             = errors.New("invalid environment")
+	ErrInvalidTrigger                 = errors.New("invalid trigger")
+	ErrDotEnvSecretConflict           = errors.New("dotenv secrets cannot be stored")
+	ErrInvalidConfigChanges           = errors.New("invalid config changes")
+	ErrInvalidConflictMode            = errors.New("onConflict must be 'fail', 'update' or 'suffix'")
+	ErrInvalidAuxiliaryFiles          = errors.New("invalid auxiliary files")
+	ErrInvalidPullRequestOptions      = errors.New("invalid pull request options")
+	ErrInvalidDirectCommit            = errors.New("invalid direct commit options")
+	ErrInvalidWorkflowPath            = errors.New("invalid workflow file path")
+
+	// Direct commit errors
+	ErrDirectCommitNotAllowed = errors.New("direct commits are not allowed")
+	ErrBranchProtected        = errors.New("branch is protected and requires a pull request")
+
+	// Config errors
+	ErrWorkflowConfigNotFound = errors.New("no stored config found for workflow")
+	ErrInvalidWorkflowConfig  = errors.New("workflow config header is invalid")
+	ErrWorkflowFileNotFound   = errors.New("workflow file not found on the default branch")
+	ErrRepositoryNotFound     = errors.New("repository not found or not accessible")
+
+	// ErrWorkflowAlreadyExists is returned when a workflow file or a pending
+	// pull request for it already exists
+	ErrWorkflowAlreadyExists = github.ErrWorkflowAlreadyExists
+
+	// Upgrade errors
+	ErrUpgradeJobNotFound = errors.New("upgrade job not found")
 
 	// Template errors
 	ErrTemplateGenerationFailed = errors.New("failed to generate workflow template")
 	ErrInvalidYAMLGenerated     = errors.New("generated YAML is invalid")
+	ErrInvalidWorkflowYAML      = errors.New("workflow YAML is invalid")
 )
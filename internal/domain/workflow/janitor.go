@@ -0,0 +1,171 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+var (
+	// workflowBranchPrefixes are the branches the service opens workflow pull requests from
+	workflowBranchPrefixes = []string{"workflow/", "update-workflow/"}
+	// generatedBranchPattern matches the branches CreateWorkflow and
+	// UpdateWorkflow create, <prefix><name>-<10 digit unix timestamp>,
+	// capturing the workflow name
+	generatedBranchPattern = regexp.MustCompile(`^(?:workflow|update-workflow)/([A-Za-z0-9_.-]+)-\d{10}$`)
+)
+
+// rollbackBranch deletes a branch whose pull request could not be opened. It
+// runs even when ctx is cancelled, since a cancelled request is a common
+// reason for the failure.
+func (s *Service) rollbackBranch(ctx context.Context, token, owner, repo, branchName string) {
+	ctx = context.WithoutCancel(ctx)
+	err := s.githubClient.DeleteBranch(ctx, token, owner, repo, branchName)
+	if err != nil && !errors.Is(err, github.ErrNotFound) {
+		logger.Error().Err(err).Str("owner", owner).Str("repo", repo).Str("branch", branchName).Msg("Failed to delete orphaned branch")
+		return
+	}
+	logger.Info().Str("owner", owner).Str("repo", repo).Str("branch", branchName).Msg("Deleted orphaned branch")
+}
+
+// CleanupStaleBranches deletes the generated workflow branches of a repository
+// that the target records, have no open pull request and whose last commit is
+// older than maxAge. Branches whose commit cannot be read are skipped. It
+// returns the names of the deleted branches.
+func (s *Service) CleanupStaleBranches(ctx context.Context, target JanitorTarget, maxAge time.Duration) ([]string, error) {
+	token, owner, repo := target.Token, target.Owner, target.Repository
+
+	var refs []github.Ref
+	for _, prefix := range workflowBranchPrefixes {
+		matching, err := s.githubClient.ListBranchRefs(ctx, token, owner, repo, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+		refs = append(refs, matching...)
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	pulls, err := s.githubClient.ListOpenPullRequests(ctx, token, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	open := make(map[string]bool, len(pulls))
+	for _, pr := range pulls {
+		open[pr.Head.Ref] = true
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	var deleted []string
+	for _, ref := range refs {
+		branchName := strings.TrimPrefix(ref.Ref, "refs/heads/")
+		if open[branchName] || !target.recorded(branchName) {
+			continue
+		}
+
+		commit, err := s.githubClient.GetCommit(ctx, token, owner, repo, ref.Object.SHA)
+		if err != nil {
+			logger.Warn().Err(err).Str("owner", owner).Str("repo", repo).Str("branch", branchName).Msg("Failed to get head commit of workflow branch, skipping it")
+			continue
+		}
+		if commit.Committer.Date.After(cutoff) {
+			continue
+		}
+
+		err = s.githubClient.DeleteBranch(ctx, token, owner, repo, branchName)
+		if errors.Is(err, github.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete %s: %w", branchName, err)
+		}
+		deleted = append(deleted, branchName)
+	}
+	return deleted, nil
+}
+
+// JanitorTarget is a repository the branch janitor cleans up, with the token
+// of a user who created workflows in it
+type JanitorTarget struct {
+	Owner      string
+	Repository string
+	Token      string
+	// WorkflowNames are the workflows recorded in the repository's history
+	WorkflowNames []string
+	// Branches are the head branches of the repository's tracked pull requests
+	Branches []string
+}
+
+// recorded reports whether branch was generated by the service for a workflow
+// the target records, or is the head of one of its tracked pull requests
+func (t JanitorTarget) recorded(branch string) bool {
+	match := generatedBranchPattern.FindStringSubmatch(branch)
+	if match == nil {
+		return false
+	}
+	return slices.Contains(t.Branches, branch) || slices.Contains(t.WorkflowNames, match[1])
+}
+
+// BranchJanitor periodically deletes stale workflow branches that were left
+// without an open pull request
+type BranchJanitor struct {
+	service *Service
+	targets func(ctx context.Context) ([]JanitorTarget, error)
+	maxAge  time.Duration
+}
+
+// NewBranchJanitor creates a janitor that cleans the repositories returned by
+// targets of workflow branches older than maxAge, which must be positive
+func NewBranchJanitor(service *Service, targets func(ctx context.Context) ([]JanitorTarget, error), maxAge time.Duration) (*BranchJanitor, error) {
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("branch janitor max age must be positive, got %v", maxAge)
+	}
+	return &BranchJanitor{service: service, targets: targets, maxAge: maxAge}, nil
+}
+
+// Run cleans up every interval until ctx is cancelled
+func (j *BranchJanitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.Sweep(ctx)
+		}
+	}
+}
+
+// Sweep cleans up every target once. Failures are logged and do not stop the
+// remaining targets.
+func (j *BranchJanitor) Sweep(ctx context.Context) {
+	targets, err := j.targets(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to list repositories for branch cleanup")
+		return
+	}
+
+	total := 0
+	for _, target := range targets {
+		deleted, err := j.service.CleanupStaleBranches(ctx, target, j.maxAge)
+		total += len(deleted)
+		if len(deleted) > 0 {
+			logger.Info().Str("owner", target.Owner).Str("repo", target.Repository).Strs("branches", deleted).Msg("Deleted stale workflow branches")
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("owner", target.Owner).Str("repo", target.Repository).Msg("Failed to clean up stale workflow branches")
+		}
+	}
+
+	logger.Info().Int("repositories", len(targets)).Int("deleted", total).Msg("Stale workflow branch cleanup finished")
+}
@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJanitorTargetRecorded(t *testing.T) {
+	target := JanitorTarget{
+		WorkflowNames: []string{"deploy"},
+		Branches:      []string{"update-workflow/release-1700000001"},
+	}
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{"workflow/deploy-1700000000", true},
+		{"update-workflow/deploy-1700000000", true},
+		{"update-workflow/release-1700000001", true},
+		{"workflow/release-1700000000", false},
+		{"workflow/deploy-2024", false},
+		{"workflow/deploy-17000000000", false},
+		{"workflow/deploy-v2", false},
+		{"feature/deploy-1700000000", false},
+	}
+	for _, tt := range tests {
+		if got := target.recorded(tt.branch); got != tt.want {
+			t.Errorf("recorded(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestNewBranchJanitorRejectsNonPositiveMaxAge(t *testing.T) {
+	for _, maxAge := range []time.Duration{0, -time.Hour} {
+		if _, err := NewBranchJanitor(nil, nil, maxAge); err == nil {
+			t.Errorf("NewBranchJanitor(maxAge=%v) succeeded, want an error", maxAge)
+		}
+	}
+}
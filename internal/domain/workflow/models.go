@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,29 +21,148 @@ type Request struct {
 	Owner                  string                  `json:"owner" binding:"required"`
 	Repository             string                  `json:"repository" binding:"required"`
 	WorkflowName           string                  `json:"workflowName" binding:"required"`
-	DeploymentType         DeploymentType          `json:"deploymentType" binding:"required,oneof=ec2 kubernetes"`
+	DeploymentType         DeploymentType          `json:"deploymentType" binding:"required"`
 	Projects               []Project               `json:"projects" binding:"required,min=1,dive"`
 	EC2CommonFields        *EC2CommonFields        `json:"ec2CommonFields"`
 	EC2Projects            []EC2Project            `json:"ec2Projects"`
 	KubernetesCommonFields *KubernetesCommonFields `json:"kubernetesCommonFields"`
 	KubernetesProjects     []KubernetesProject     `json:"kubernetesProjects"`
+
+	// Environments are the deployment stages of the workflow, selected by the
+	// pushed tag. DefaultEnvironments are used when none are given.
+	Environments []Environment `json:"environments" binding:"omitempty,dive"`
+
+	// Triggers selects the events that run the workflow. Without it the
+	// workflow runs on pushes of tags matching an environment.
+	Triggers *Triggers `json:"triggers"`
+
+	// OnConflict selects what happens when a workflow of the same name already
+	// exists or is pending in an open pull request; ConflictFail by default
+	OnConflict ConflictMode `json:"onConflict,omitempty"`
+
+	// AuxiliaryFiles selects files added to the same pull request as a new
+	// workflow; they are not added when an existing workflow is updated
+	AuxiliaryFiles *AuxiliaryFiles `json:"auxiliaryFiles,omitempty"`
+
+	// PullRequest customizes the pull request opened for the workflow
+	PullRequest *PullRequestOptions `json:"pullRequest,omitempty"`
+
+	// DirectCommit commits the workflow without a pull request, where the
+	// server policy allows it and the branch is not protected
+	DirectCommit *DirectCommitOptions `json:"directCommit,omitempty"`
+
+	// Config carries fields for deployment types registered outside the built-ins,
+	// validated against the field schema of the type's DeploymentDefinition
+	Config map[string]interface{} `json:"config,omitempty"`
 }
 
-// Validate validates the workflow request
-func (r *Request) Validate() error {
-	if r.DeploymentType == DeploymentTypeEC2 {
-		if r.EC2CommonFields == nil {
-			return ErrEC2CommonFieldsRequired
-		}
-		if len(r.EC2Projects) == 0 {
-			return ErrEC2ProjectsRequired
+// ProjectFor returns the common project configuration matching a deployment
+// project, by ID first and then by name
+func (r *Request) ProjectFor(id, name string) *Project {
+	for i := range r.Projects {
+		if r.Projects[i].ID == id {
+			return &r.Projects[i]
 		}
-	} else if r.DeploymentType == DeploymentTypeKubernetes {
-		if r.KubernetesCommonFields == nil {
-			return ErrKubernetesCommonFieldsRequired
+	}
+	for i := range r.Projects {
+		if r.Projects[i].Name == name {
+			return &r.Projects[i]
 		}
-		if len(r.KubernetesProjects) == 0 {
-			return ErrKubernetesProjectsRequired
+	}
+	return nil
+}
+
+// Stages returns the environments the workflow deploys to
+func (r *Request) Stages() []Environment {
+	if len(r.Environments) == 0 {
+		return DefaultEnvironments
+	}
+	return r.Environments
+}
+
+// Environment represents a deployment stage of the generated workflow
+type Environment struct {
+	Name string `json:"name" binding:"required"`
+	// TagPattern is a GitHub filter pattern; a pushed tag deploys to the first environment it matches
+	TagPattern string `json:"tagPattern" binding:"required"`
+	// GitHubEnvironment optionally gates the deployment behind a protected GitHub environment
+	GitHubEnvironment string `json:"githubEnvironment"`
+}
+
+// DefaultEnvironments deploy release candidate tags to testing and release tags to production
+var DefaultEnvironments = []Environment{
+	{Name: "testing", TagPattern: "v[0-9]+.[0-9]+.[0-9]+-rc[0-9]+"},
+	{Name: "production", TagPattern: "v[0-9]+.[0-9]+.[0-9]+"},
+}
+
+// TagTriggers returns the tag patterns that start the workflow
+func (r *Request) TagTriggers() []string {
+	if r.Triggers != nil && r.Triggers.Tags != nil {
+		return r.Triggers.Tags
+	}
+	patterns := make([]string, 0, len(r.Stages()))
+	for _, env := range r.Stages() {
+		patterns = append(patterns, env.TagPattern)
+	}
+	return patterns
+}
+
+// Triggers represents the events that start a generated workflow
+type Triggers struct {
+	// Tags are tag filter patterns; nil means the tag patterns of the environments
+	Tags []string `json:"tags"`
+	// Branches are branch filter patterns for push events
+	Branches         []string                 `json:"branches,omitempty"`
+	PullRequest      *PullRequestTrigger      `json:"pullRequest,omitempty"`
+	WorkflowDispatch *WorkflowDispatchTrigger `json:"workflowDispatch,omitempty"`
+	// Schedules are cron expressions in UTC
+	Schedules []string `json:"schedules,omitempty"`
+
+	// Environment is deployed to by runs whose ref matches no environment
+	// tag pattern. A workflow_dispatch input named "environment" overrides it.
+	Environment string `json:"environment,omitempty"`
+}
+
+// PullRequestTrigger represents a pull_request event filter
+type PullRequestTrigger struct {
+	Branches []string `json:"branches,omitempty"`
+	Types    []string `json:"types,omitempty"`
+}
+
+// WorkflowDispatchTrigger represents a manually started workflow
+type WorkflowDispatchTrigger struct {
+	Inputs []DispatchInput `json:"inputs,omitempty" binding:"omitempty,dive"`
+}
+
+// DispatchInputType represents the type of a workflow_dispatch input
+type DispatchInputType string
+
+const (
+	DispatchInputString      DispatchInputType = "string"
+	DispatchInputBoolean     DispatchInputType = "boolean"
+	DispatchInputNumber      DispatchInputType = "number"
+	DispatchInputChoice      DispatchInputType = "choice"
+	DispatchInputEnvironment DispatchInputType = "environment"
+)
+
+// DispatchInput represents a typed workflow_dispatch input
+type DispatchInput struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Type        DispatchInputType `json:"type" binding:"required"`
+	Required    bool              `json:"required"`
+	Default     string            `json:"default"`
+	Options     []string          `json:"options,omitempty"`
+}
+
+// DispatchInput returns the workflow_dispatch input with the given name
+func (t *Triggers) DispatchInput(name string) *DispatchInput {
+	if t == nil || t.WorkflowDispatch == nil {
+		return nil
+	}
+	for i := range t.WorkflowDispatch.Inputs {
+		if t.WorkflowDispatch.Inputs[i].Name == name {
+			return &t.WorkflowDispatch.Inputs[i]
 		}
 	}
 	return nil
@@ -56,6 +176,29 @@ type Project struct {
 	DockerfilePath    string `json:"dockerfilePath" binding:"required"`
 	DotEnvTesting     string `json:"dotEnvTesting"`
 	DotEnvProduction  string `json:"dotEnvProduction"`
+
+	// DotEnv holds dotenv file contents by environment name and takes
+	// precedence over DotEnvTesting and DotEnvProduction
+	DotEnv map[string]string `json:"dotEnv,omitempty"`
+
+	// DotEnvSecrets names, by environment name, repository secrets stored by
+	// an earlier request. Stored configs keep them in place of the redacted
+	// dotenv entries so regenerated workflows still reference the secrets.
+	DotEnvSecrets map[string]string `json:"dotEnvSecrets,omitempty"`
+}
+
+// DotEnvFor returns the dotenv file contents of the project for an environment
+func (p *Project) DotEnvFor(environment string) string {
+	if content, ok := p.DotEnv[environment]; ok {
+		return content
+	}
+	switch environment {
+	case "testing":
+		return p.DotEnvTesting
+	case "production":
+		return p.DotEnvProduction
+	}
+	return ""
 }
 
 // EC2CommonFields represents shared EC2 configuration
@@ -98,14 +241,42 @@ type KubernetesProject struct {
 
 // Response represents a workflow creation response
 type Response struct {
-	Owner        string    `json:"owner"`
-	Repository   string    `json:"repository"`
-	WorkflowName string    `json:"workflowName"`
-	FilePath     string    `json:"filePath"`
-	FileURL      string    `json:"fileUrl"`
-	ContentSHA   string    `json:"contentSha"`
-	Message      string    `json:"message"`
-	CreatedAt    time.Time `json:"createdAt"`
+	Owner        string `json:"owner"`
+	Repository   string `json:"repository"`
+	WorkflowName string `json:"workflowName"`
+	FilePath     string `json:"filePath"`
+	FileURL      string `json:"fileUrl"`
+	PRNumber     int    `json:"prNumber,omitempty"`
+	ContentSHA   string `json:"contentSha"`
+	// Branch is the pull request head, or the target branch of a direct
+	// commit, and CommitSHA the commit written to it. BaseBranch is only set
+	// for pull requests.
+	Branch     string    `json:"branch,omitempty"`
+	BaseBranch string    `json:"baseBranch,omitempty"`
+	CommitSHA  string    `json:"commitSha,omitempty"`
+	Message    string    `json:"message"`
+	CreatedAt  time.Time `json:"createdAt"`
+
+	// Files lists every file written by the pull request, including auxiliary files
+	Files []string `json:"files,omitempty"`
+
+	// Secrets lists the repository secrets created from dotenv files
+	Secrets []DotEnvSecret `json:"secrets,omitempty"`
+
+	// Warnings lists pull request metadata that could not be applied
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PreviewResponse represents a generated workflow and its validation results
+type PreviewResponse struct {
+	WorkflowName   string         `json:"workflow_name"`
+	DeploymentType DeploymentType `json:"deployment_type"`
+	YAMLContent    string         `json:"yaml_content"`
+	Valid          bool           `json:"valid"`
+	Diagnostics    []Diagnostic   `json:"diagnostics"`
+	Secrets        []DotEnvSecret `json:"secrets"`
+	// Diff compares the workflow with the file already in the repository, if any
+	Diff *WorkflowDiff `json:"diff,omitempty"`
 }
 
 // File represents a workflow file
@@ -118,21 +289,47 @@ type File struct {
 	DownloadURL string `json:"downloadUrl"`
 }
 
+// HistoryAction represents the kind of workflow change that was attempted
+type HistoryAction string
+
+const (
+	HistoryActionCreate     HistoryAction = "create"
+	HistoryActionUpdate     HistoryAction = "update"
+	HistoryActionRegenerate HistoryAction = "regenerate"
+	HistoryActionUpgrade    HistoryAction = "upgrade"
+)
+
+// History statuses
+const (
+	HistoryStatusSuccess = "success"
+	HistoryStatusFailed  = "failed"
+)
+
 // History represents workflow creation history
 type History struct {
-	ID             uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	UserID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
-	Owner          string         `gorm:"not null" json:"owner"`
-	Repository     string         `gorm:"not null" json:"repository"`
-	WorkflowName   string         `gorm:"not null" json:"workflow_name"`
-	DeploymentType DeploymentType `gorm:"type:varchar(20);not null" json:"deployment_type"`
-	FilePath       string         `json:"file_path"`
-	ContentSHA     string         `json:"content_sha"`
-	Status         string         `gorm:"type:varchar(20);not null" json:"status"`
-	ErrorMessage   *string        `json:"error_message,omitempty"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID            uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Action            HistoryAction   `gorm:"type:varchar(20);not null;default:create" json:"action"`
+	Owner             string          `gorm:"not null;index:idx_workflow_histories_repo" json:"owner"`
+	Repository        string          `gorm:"not null;index:idx_workflow_histories_repo" json:"repository"`
+	WorkflowName      string          `gorm:"not null" json:"workflow_name"`
+	DeploymentType    DeploymentType  `gorm:"type:varchar(20);not null" json:"deployment_type"`
+	FilePath          string          `json:"file_path"`
+	ContentSHA        string          `json:"content_sha"`
+	RequestPayload    json.RawMessage `gorm:"type:jsonb" json:"request_payload,omitempty"`
+	YAMLContent       string          `gorm:"type:text" json:"yaml_content,omitempty"`
+	PullRequestURL    string          `json:"pull_request_url,omitempty"`
+	PullRequestNumber int             `json:"pull_request_number,omitempty"`
+	Status            string          `gorm:"type:varchar(20);not null;index" json:"status"`
+	ErrorMessage      *string         `json:"error_message,omitempty"`
+	CreatedAt         time.Time       `gorm:"index" json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// TableName returns the table name for the History model
+func (History) TableName() string {
+	return "workflow_histories"
 }
 
 // BeforeCreate hook
@@ -143,6 +340,90 @@ func (h *History) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// HistoryFilter narrows down workflow history queries; zero values match everything
+type HistoryFilter struct {
+	Owner      string
+	Repository string
+	UserID     *uuid.UUID
+	Status     string
+	Action     HistoryAction
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+}
+
+// Pull request states
+const (
+	PullRequestStateOpen   = "open"
+	PullRequestStateMerged = "merged"
+	PullRequestStateClosed = "closed"
+)
+
+// Pull request check statuses, combining check runs and commit statuses of the head commit
+const (
+	ChecksStatusNone    = "none"
+	ChecksStatusPending = "pending"
+	ChecksStatusSuccess = "success"
+	ChecksStatusFailure = "failure"
+)
+
+// Pull request review states
+const (
+	ReviewStatePending          = "pending"
+	ReviewStateApproved         = "approved"
+	ReviewStateChangesRequested = "changes_requested"
+)
+
+// TrackedPullRequest is a pull request opened by the workflow manager, with
+// its state as of the last refresh from GitHub
+type TrackedPullRequest struct {
+	ID           uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Action       HistoryAction `gorm:"type:varchar(20);not null" json:"action"`
+	Owner        string        `gorm:"not null;uniqueIndex:idx_workflow_pull_requests_number" json:"owner"`
+	Repository   string        `gorm:"not null;uniqueIndex:idx_workflow_pull_requests_number" json:"repository"`
+	Number       int           `gorm:"not null;uniqueIndex:idx_workflow_pull_requests_number" json:"number"`
+	WorkflowName string        `gorm:"not null" json:"workflow_name"`
+	FilePath     string        `json:"file_path"`
+	URL          string        `json:"url"`
+	Title        string        `json:"title"`
+	HeadBranch   string        `json:"head_branch"`
+	BaseBranch   string        `json:"base_branch"`
+	HeadSHA      string        `gorm:"index" json:"head_sha"`
+	State        string        `gorm:"type:varchar(20);not null;index" json:"state"`
+	Draft        bool          `json:"draft"`
+	ChecksStatus string        `gorm:"type:varchar(20);not null" json:"checks_status"`
+	ReviewState  string        `gorm:"type:varchar(20);not null" json:"review_state"`
+	MergedAt     *time.Time    `json:"merged_at,omitempty"`
+	ClosedAt     *time.Time    `json:"closed_at,omitempty"`
+	RefreshedAt  *time.Time    `json:"refreshed_at,omitempty"`
+	CreatedAt    time.Time     `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// TableName returns the table name for the TrackedPullRequest model
+func (TrackedPullRequest) TableName() string {
+	return "workflow_pull_requests"
+}
+
+// BeforeCreate hook
+func (p *TrackedPullRequest) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// PullRequestFilter narrows down tracked pull request queries; zero values match everything
+type PullRequestFilter struct {
+	Owner        string
+	Repository   string
+	WorkflowName string
+	UserID       *uuid.UUID
+	State        string
+	Limit        int
+}
+
 // FileContentResponse represents workflow file content
 type FileContentResponse struct {
 	Name    string `json:"name"`
@@ -160,4 +441,15 @@ type UpdateWorkflowRequest struct {
 	Content       string `json:"content" binding:"required"`
 	SHA           string `json:"sha" binding:"required"`
 	CommitMessage string `json:"commitMessage"`
+
+	// PullRequest customizes the pull request; SHA must then be the blob SHA
+	// of the file on its base branch
+	PullRequest *PullRequestOptions `json:"pullRequest,omitempty"`
+
+	// DirectCommit commits the update without a pull request; SHA must then be
+	// the blob SHA of the file on the target branch
+	DirectCommit *DirectCommitOptions `json:"directCommit,omitempty"`
+
+	// Request is the generation request of the content, if it was generated
+	Request *Request `json:"-"`
 }
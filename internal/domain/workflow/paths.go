@@ -0,0 +1,115 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// normalizeProjectPaths cleans the Docker paths of every project to
+// repository-relative form, e.g. "./services/api/" becomes "services/api"
+func normalizeProjectPaths(req *Request) error {
+	for i := range req.Projects {
+		project := &req.Projects[i]
+
+		contextPath, err := normalizeRepoPath(project.DockerContextPath)
+		if err != nil {
+			return fmt.Errorf("%w: project '%s' dockerContextPath %v", ErrInvalidProjectPath, project.Name, err)
+		}
+		dockerfilePath, err := normalizeRepoPath(project.DockerfilePath)
+		if err != nil {
+			return fmt.Errorf("%w: project '%s' dockerfilePath %v", ErrInvalidProjectPath, project.Name, err)
+		}
+		if dockerfilePath == "." {
+			return fmt.Errorf("%w: project '%s' dockerfilePath must point to a file", ErrInvalidProjectPath, project.Name)
+		}
+
+		project.DockerContextPath = contextPath
+		project.DockerfilePath = dockerfilePath
+	}
+	return nil
+}
+
+// normalizeRepoPath returns p relative to the repository root, with "." for the root itself
+func normalizeRepoPath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return "", errors.New("is empty")
+	}
+	if strings.HasPrefix(p, "/") || strings.Contains(p, "\\") {
+		return "", fmt.Errorf("'%s' must be a relative path", p)
+	}
+
+	cleaned := path.Clean(p)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("'%s' must not leave the repository", p)
+	}
+	return cleaned, nil
+}
+
+// VerifyProjectPaths checks that every project's Docker context directory and
+// Dockerfile exist on the default branch of the repository
+func (s *Service) VerifyProjectPaths(ctx context.Context, token string, req *Request) error {
+	defaultBranch, err := s.githubClient.GetDefaultBranch(ctx, token, req.Owner, req.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to get default branch: %w", err)
+	}
+
+	// Projects frequently share a context, so each path is only looked up once
+	types := make(map[string]string)
+	lookup := func(p string) (string, error) {
+		if p == "." {
+			return "dir", nil
+		}
+		if t, ok := types[p]; ok {
+			return t, nil
+		}
+		t, err := s.githubClient.GetPathType(ctx, token, req.Owner, req.Repository, p, defaultBranch)
+		if errors.Is(err, github.ErrNotFound) {
+			t, err = "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		types[p] = t
+		return t, nil
+	}
+
+	var problems []string
+	for _, project := range req.Projects {
+		checks := []struct {
+			field, path, want string
+		}{
+			{"dockerContextPath", project.DockerContextPath, "dir"},
+			{"dockerfilePath", project.DockerfilePath, "file"},
+		}
+		for _, check := range checks {
+			t, err := lookup(check.path)
+			if err != nil {
+				return fmt.Errorf("failed to check %s of project '%s': %w", check.field, project.Name, err)
+			}
+			switch {
+			case t == "":
+				problems = append(problems, fmt.Sprintf("project '%s' %s '%s' does not exist", project.Name, check.field, check.path))
+			case t != check.want:
+				problems = append(problems, fmt.Sprintf("project '%s' %s '%s' is not a %s", project.Name, check.field, check.path, check.want))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		logger.Warn().
+			Str("owner", req.Owner).
+			Str("repo", req.Repository).
+			Str("branch", defaultBranch).
+			Strs("problems", problems).
+			Msg("Project paths not found in repository")
+		return fmt.Errorf("%w on branch '%s': %s", ErrProjectPathNotFound, defaultBranch, strings.Join(problems, "; "))
+	}
+	return nil
+}
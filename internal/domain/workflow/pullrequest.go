@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// PullRequestOptions customizes the pull request opened for a workflow change
+type PullRequestOptions struct {
+	// BaseBranch is the branch the pull request merges into; the default branch when empty
+	BaseBranch string `json:"baseBranch,omitempty"`
+	// Title and Body are Go text/templates rendered with PullRequestTemplateData
+	Title         string   `json:"title,omitempty"`
+	Body          string   `json:"body,omitempty"`
+	Reviewers     []string `json:"reviewers,omitempty"`
+	TeamReviewers []string `json:"teamReviewers,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	Assignees     []string `json:"assignees,omitempty"`
+	Draft         bool     `json:"draft,omitempty"`
+}
+
+// PullRequestTemplateData is available to pull request title and body templates
+type PullRequestTemplateData struct {
+	// Action is "create" or "update"
+	Action       HistoryAction
+	Owner        string
+	Repository   string
+	WorkflowName string
+	FilePath     string
+	// Files lists every file the pull request writes
+	Files      []string
+	Branch     string
+	BaseBranch string
+	// Request is the redacted generation request; nil for edited files
+	Request *Request
+}
+
+// pullRequestFuncs are the functions available to pull request templates
+var pullRequestFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// validatePullRequestOptions checks the base branch and that the templates parse
+func validatePullRequestOptions(opts *PullRequestOptions) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.BaseBranch != "" && !isValidReleaseTag(opts.BaseBranch) {
+		return fmt.Errorf("%w: baseBranch '%s' is not a valid branch name", ErrInvalidPullRequestOptions, opts.BaseBranch)
+	}
+	if _, err := template.New("title").Funcs(pullRequestFuncs).Parse(opts.Title); err != nil {
+		return fmt.Errorf("%w: title template: %v", ErrInvalidPullRequestOptions, err)
+	}
+	if _, err := template.New("body").Funcs(pullRequestFuncs).Parse(opts.Body); err != nil {
+		return fmt.Errorf("%w: body template: %v", ErrInvalidPullRequestOptions, err)
+	}
+	return nil
+}
+
+// baseBranch returns the branch a pull request merges into
+func (o *PullRequestOptions) baseBranch(defaultBranch string) string {
+	if o == nil || o.BaseBranch == "" {
+		return defaultBranch
+	}
+	return o.BaseBranch
+}
+
+// renderPullRequest returns the title and body of a pull request, rendered
+// from the templates in opts where set and the defaults otherwise
+func renderPullRequest(opts *PullRequestOptions, data PullRequestTemplateData, defaultTitle, defaultBody string) (string, string, error) {
+	if opts == nil {
+		return defaultTitle, defaultBody, nil
+	}
+	if data.Request != nil {
+		data.Request = data.Request.Redacted()
+	}
+
+	render := func(name, text, fallback string) (string, error) {
+		if text == "" {
+			return fallback, nil
+		}
+		tmpl, err := template.New(name).Funcs(pullRequestFuncs).Parse(text)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s template: %v", ErrInvalidPullRequestOptions, name, err)
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return "", fmt.Errorf("%w: %s template: %v", ErrInvalidPullRequestOptions, name, err)
+		}
+		return b.String(), nil
+	}
+
+	title, err := render("title", opts.Title, defaultTitle)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := render("body", opts.Body, defaultBody)
+	if err != nil {
+		return "", "", err
+	}
+	if title = strings.TrimSpace(title); title == "" {
+		title = defaultTitle
+	}
+	return title, body, nil
+}
+
+// openPullRequest opens a pull request and applies the reviewers, labels and
+// assignees of opts. The pull request is kept when applying them fails; the
+// failures are returned as warnings instead.
+func (s *Service) openPullRequest(ctx context.Context, token, owner, repo, head, base, title, body string, opts *PullRequestOptions) (string, int, []string, error) {
+	draft := opts != nil && opts.Draft
+	prURL, prNumber, err := s.githubClient.CreatePullRequest(ctx, token, owner, repo, head, base, title, body, draft)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if opts == nil {
+		return prURL, prNumber, nil, nil
+	}
+
+	var warnings []string
+	if len(opts.Reviewers) > 0 || len(opts.TeamReviewers) > 0 {
+		if err := s.githubClient.RequestReviewers(ctx, token, owner, repo, prNumber, opts.Reviewers, opts.TeamReviewers); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to request reviewers: %v", err))
+		}
+	}
+	if len(opts.Labels) > 0 {
+		if err := s.githubClient.AddLabels(ctx, token, owner, repo, prNumber, opts.Labels); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to add labels: %v", err))
+		}
+	}
+	if len(opts.Assignees) > 0 {
+		if err := s.githubClient.AddAssignees(ctx, token, owner, repo, prNumber, opts.Assignees); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to add assignees: %v", err))
+		}
+	}
+	return prURL, prNumber, warnings, nil
+}
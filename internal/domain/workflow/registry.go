@@ -0,0 +1,204 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/template"
+)
+
+var releaseTagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// FieldType represents the type of a deployment-specific config field
+type FieldType string
+
+const (
+	FieldTypeString  FieldType = "string"
+	FieldTypeNumber  FieldType = "number"
+	FieldTypeBoolean FieldType = "boolean"
+	FieldTypeList    FieldType = "list"
+)
+
+// FieldSchema describes a single field a deployment type reads from Request.Config
+type FieldSchema struct {
+	Name        string    `json:"name"`
+	Type        FieldType `json:"type"`
+	Required    bool      `json:"required"`
+	Description string    `json:"description,omitempty"`
+}
+
+// DeploymentDefinition describes a registered deployment type
+type DeploymentDefinition struct {
+	Type        DeploymentType     `json:"type"`
+	DisplayName string             `json:"displayName"`
+	Description string             `json:"description,omitempty"`
+	Fields      []FieldSchema      `json:"fields"`
+	Generator   template.Generator `json:"-"`
+
+	// Validate runs type-specific checks after the field schema has been applied
+	Validate func(req *Request) error `json:"-"`
+}
+
+// Registry holds the deployment types the workflow service can generate
+type Registry struct {
+	mu          sync.RWMutex
+	definitions map[DeploymentType]DeploymentDefinition
+}
+
+// NewRegistry creates an empty deployment type registry
+func NewRegistry() *Registry {
+	return &Registry{
+		definitions: make(map[DeploymentType]DeploymentDefinition),
+	}
+}
+
+// Register adds a deployment type to the registry
+func (r *Registry) Register(def DeploymentDefinition) error {
+	if def.Type == "" {
+		return fmt.Errorf("deployment type is required")
+	}
+	if def.Generator == nil {
+		return fmt.Errorf("generator is required for deployment type '%s'", def.Type)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.definitions[def.Type]; exists {
+		return fmt.Errorf("deployment type '%s' is already registered", def.Type)
+	}
+	r.definitions[def.Type] = def
+	return nil
+}
+
+// Get returns the definition for a deployment type
+func (r *Registry) Get(deploymentType DeploymentType) (DeploymentDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.definitions[deploymentType]
+	return def, ok
+}
+
+// List returns all registered deployment types sorted by type
+func (r *Registry) List() []DeploymentDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]DeploymentDefinition, 0, len(r.definitions))
+	for _, def := range r.definitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Type < defs[j].Type
+	})
+	return defs
+}
+
+// Validate checks a request against the schema and validator of its deployment type
+func (r *Registry) Validate(req *Request) error {
+	def, ok := r.Get(req.DeploymentType)
+	if !ok {
+		return fmt.Errorf("%w: '%s'", ErrInvalidDeploymentType, req.DeploymentType)
+	}
+
+	for _, field := range def.Fields {
+		value, present := req.Config[field.Name]
+		if !present || value == nil {
+			if field.Required {
+				return fmt.Errorf("%w: %s", ErrConfigFieldRequired, field.Name)
+			}
+			continue
+		}
+		if !field.Type.matches(value) {
+			return fmt.Errorf("%w: %s must be a %s", ErrConfigFieldInvalid, field.Name, field.Type)
+		}
+	}
+
+	if def.Validate != nil {
+		return def.Validate(req)
+	}
+	return nil
+}
+
+// matches reports whether a decoded JSON value has the expected field type
+func (t FieldType) matches(value interface{}) bool {
+	switch t {
+	case FieldTypeString:
+		_, ok := value.(string)
+		return ok
+	case FieldTypeNumber:
+		_, ok := value.(float64)
+		return ok
+	case FieldTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case FieldTypeList:
+		_, ok := value.([]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+// registerBuiltins registers the deployment types shipped with the service
+func registerBuiltins(r *Registry, store *template.Store) {
+	builtins := []DeploymentDefinition{
+		{
+			Type:        DeploymentTypeEC2,
+			DisplayName: "Amazon EC2",
+			Description: "Build images and deploy them to EC2 instances through Jenkins",
+			Fields:      []FieldSchema{},
+			Generator:   template.NewEC2Generator(store),
+			Validate:    validateEC2Request,
+		},
+		{
+			Type:        DeploymentTypeKubernetes,
+			DisplayName: "Kubernetes",
+			Description: "Build images and deploy them to Kubernetes with Helm through Jenkins",
+			Fields:      []FieldSchema{},
+			Generator:   template.NewKubernetesGenerator(store),
+			Validate:    validateKubernetesRequest,
+		},
+	}
+
+	for _, def := range builtins {
+		if err := r.Register(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func validateEC2Request(req *Request) error {
+	if req.EC2CommonFields == nil {
+		return ErrEC2CommonFieldsRequired
+	}
+	if len(req.EC2Projects) == 0 {
+		return ErrEC2ProjectsRequired
+	}
+	if !isValidReleaseTag(req.EC2CommonFields.ReleaseTag) {
+		return ErrInvalidReleaseTag
+	}
+	return nil
+}
+
+func validateKubernetesRequest(req *Request) error {
+	if req.KubernetesCommonFields == nil {
+		return ErrKubernetesCommonFieldsRequired
+	}
+	if len(req.KubernetesProjects) == 0 {
+		return ErrKubernetesProjectsRequired
+	}
+	if !isValidReleaseTag(req.KubernetesCommonFields.ReleaseTag) {
+		return ErrInvalidReleaseTag
+	}
+	return nil
+}
+
+// isValidReleaseTag reports whether tag can be used as the ref of a reusable workflow
+func isValidReleaseTag(tag string) bool {
+	return releaseTagPattern.MatchString(tag) && !strings.Contains(tag, "..")
+}
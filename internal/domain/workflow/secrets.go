@@ -0,0 +1,266 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// maxSecretSize is the largest value GitHub accepts for an Actions secret
+const maxSecretSize = 48 * 1024
+
+var (
+	dotEnvKeyPattern   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	secretKeyPattern   = regexp.MustCompile(`(?i)(^|_)(SECRET|PASSWORD|PASSWD|PASS|PWD|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIALS?|ACCESS_?KEY|AUTH|DSN|CONN(ECTION)?_?STRING|DATABASE_URL|SALT|SIGNING_?KEY)(_|$)`)
+	secretNameReplacer = regexp.MustCompile(`[^A-Z0-9]+`)
+	secretNamePattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// DotEnvSecret is the secret part of one project's dotenv file for an environment
+type DotEnvSecret struct {
+	Name        string   `json:"name"`
+	Project     string   `json:"project"`
+	Environment string   `json:"environment"`
+	Keys        []string `json:"keys"`
+	Value       string   `json:"-"`
+}
+
+// dotEnvEntry is one variable of a dotenv file together with its source lines
+type dotEnvEntry struct {
+	key   string
+	lines []string
+}
+
+// splitDotEnv separates the entries of a dotenv file whose keys look like
+// credentials from the rest. Comments, blank lines and unparsable lines stay public.
+func splitDotEnv(content string) (public string, secret string, secretKeys []string) {
+	var publicLines, secretLines []string
+	for _, entry := range parseDotEnv(content) {
+		if entry.key != "" && secretKeyPattern.MatchString(entry.key) {
+			secretLines = append(secretLines, entry.lines...)
+			secretKeys = append(secretKeys, entry.key)
+			continue
+		}
+		publicLines = append(publicLines, entry.lines...)
+	}
+
+	public = strings.Join(publicLines, "\n")
+	if strings.TrimSpace(public) == "" {
+		public = ""
+	} else if strings.HasSuffix(content, "\n") {
+		public += "\n"
+	}
+	if len(secretLines) > 0 {
+		secret = strings.Join(secretLines, "\n") + "\n"
+	}
+	return public, secret, secretKeys
+}
+
+// parseDotEnv splits a dotenv file into entries, keeping quoted values that
+// span several lines together
+func parseDotEnv(content string) []dotEnvEntry {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	var entries []dotEnvEntry
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			entries = append(entries, dotEnvEntry{lines: []string{line}})
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(trimmed, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !found || !dotEnvKeyPattern.MatchString(key) {
+			entries = append(entries, dotEnvEntry{lines: []string{line}})
+			continue
+		}
+
+		entry := dotEnvEntry{key: key, lines: []string{line}}
+		value = strings.TrimSpace(value)
+		if quote := openQuote(value); quote != 0 {
+			for i+1 < len(lines) {
+				i++
+				entry.lines = append(entry.lines, lines[i])
+				if strings.ContainsRune(lines[i], rune(quote)) {
+					break
+				}
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// openQuote returns the quote character of a value that starts a quoted
+// string without closing it, or 0
+func openQuote(value string) byte {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return 0
+	}
+	if strings.IndexByte(value[1:], value[0]) == -1 {
+		return value[0]
+	}
+	return 0
+}
+
+// dotEnvSecretName returns the repository secret holding the secret part of
+// a project's dotenv file for an environment. The workflow name keeps
+// workflows of the same repository from overwriting each other's secrets.
+// Environment secrets cannot be used because jobs that call reusable
+// workflows cannot select an environment.
+func dotEnvSecretName(workflowName, project, environment string) string {
+	name := "DOTENV_" + strings.ToUpper(workflowName) + "_" + strings.ToUpper(project) + "_" + strings.ToUpper(environment)
+	return strings.Trim(secretNameReplacer.ReplaceAllString(name, "_"), "_")
+}
+
+// PublicDotEnvFor returns the project's dotenv file for an environment without
+// the entries that are moved into a secret
+func (p *Project) PublicDotEnvFor(environment string) string {
+	public, _, _ := splitDotEnv(p.DotEnvFor(environment))
+	return public
+}
+
+// DotEnvSecretFor returns the name of the secret holding credentials from a
+// project's dotenv file for an environment, or "" if it has none
+func (r *Request) DotEnvSecretFor(project *Project, environment string) string {
+	if _, secret, _ := splitDotEnv(project.DotEnvFor(environment)); secret != "" {
+		return dotEnvSecretName(r.WorkflowName, project.Name, environment)
+	}
+	return project.DotEnvSecrets[environment]
+}
+
+// HasDotEnvSecrets reports whether any project moves dotenv entries for an environment into a secret
+func (r *Request) HasDotEnvSecrets(environment string) bool {
+	for i := range r.Projects {
+		if r.DotEnvSecretFor(&r.Projects[i], environment) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DotEnvSecrets returns the secrets a request stores, sorted by name
+func (r *Request) DotEnvSecrets() []DotEnvSecret {
+	secrets := []DotEnvSecret{}
+	for _, env := range r.Stages() {
+		for _, project := range r.Projects {
+			_, secret, keys := splitDotEnv(project.DotEnvFor(env.Name))
+			if secret == "" {
+				continue
+			}
+			secrets = append(secrets, DotEnvSecret{
+				Name:        dotEnvSecretName(r.WorkflowName, project.Name, env.Name),
+				Project:     project.Name,
+				Environment: env.Name,
+				Keys:        keys,
+				Value:       secret,
+			})
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+	return secrets
+}
+
+// validateDotEnvSecrets checks that the secrets of different projects do not
+// share a name and fit GitHub's size limit
+func validateDotEnvSecrets(req *Request) error {
+	for _, project := range req.Projects {
+		for env, name := range project.DotEnvSecrets {
+			if !secretNamePattern.MatchString(name) {
+				return fmt.Errorf("%w: project '%s' references invalid secret name '%s' for %s", ErrDotEnvSecretConflict, project.Name, name, env)
+			}
+		}
+	}
+
+	owners := make(map[string]string)
+	for _, secret := range req.DotEnvSecrets() {
+		owner := secret.Project + "/" + secret.Environment
+		if other, exists := owners[secret.Name]; exists {
+			return fmt.Errorf("%w: %s and %s both map to secret '%s'", ErrDotEnvSecretConflict, other, owner, secret.Name)
+		}
+		owners[secret.Name] = owner
+
+		if len(secret.Value) > maxSecretSize {
+			return fmt.Errorf("%w: secret '%s' exceeds %d bytes", ErrDotEnvSecretConflict, secret.Name, maxSecretSize)
+		}
+	}
+	return nil
+}
+
+// StoreDotEnvSecrets encrypts the credentials found in the request's dotenv
+// files with the repository public key and stores them as Actions secrets
+func (s *Service) StoreDotEnvSecrets(ctx context.Context, token string, req *Request) ([]DotEnvSecret, error) {
+	secrets := req.DotEnvSecrets()
+	if len(secrets) == 0 {
+		return secrets, nil
+	}
+
+	key, err := s.secretsClient.GetRepositoryPublicKey(ctx, token, req.Owner, req.Repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository public key: %w", err)
+	}
+
+	for _, secret := range secrets {
+		encrypted, err := github.EncryptSecret(key, secret.Value)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.secretsClient.PutRepositorySecret(ctx, token, req.Owner, req.Repository, secret.Name, encrypted, key.KeyID); err != nil {
+			logger.Error().Err(err).
+				Str("owner", req.Owner).
+				Str("repo", req.Repository).
+				Str("secret", secret.Name).
+				Msg("Failed to store dotenv secret")
+			return nil, fmt.Errorf("failed to store secret '%s': %w", secret.Name, err)
+		}
+	}
+
+	logger.Info().
+		Str("owner", req.Owner).
+		Str("repo", req.Repository).
+		Int("count", len(secrets)).
+		Msg("Stored dotenv secrets")
+	return secrets, nil
+}
+
+// Redacted returns a copy of the request without the dotenv entries that are
+// stored as secrets, safe to persist. The names of those secrets are kept in
+// DotEnvSecrets.
+func (r *Request) Redacted() *Request {
+	redacted := *r
+	redacted.Projects = make([]Project, len(r.Projects))
+	for i, project := range r.Projects {
+		secretNames := make(map[string]string)
+		for _, env := range r.Stages() {
+			if name := r.DotEnvSecretFor(&project, env.Name); name != "" {
+				secretNames[env.Name] = name
+			}
+		}
+		project.DotEnvSecrets = nil
+		if len(secretNames) > 0 {
+			project.DotEnvSecrets = secretNames
+		}
+
+		project.DotEnvTesting, _, _ = splitDotEnv(project.DotEnvTesting)
+		project.DotEnvProduction, _, _ = splitDotEnv(project.DotEnvProduction)
+		if project.DotEnv != nil {
+			dotEnv := make(map[string]string, len(project.DotEnv))
+			for env, content := range project.DotEnv {
+				dotEnv[env], _, _ = splitDotEnv(content)
+			}
+			project.DotEnv = dotEnv
+		}
+		redacted.Projects[i] = project
+	}
+	return &redacted
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -14,52 +15,147 @@ import (
 
 // Service handles workflow business logic
 type Service struct {
-	githubClient *github.WorkflowClient
-	ec2Template  *template.EC2Generator
-	k8sTemplate  *template.KubernetesGenerator
+	githubClient  *github.WorkflowClient
+	secretsClient *github.SecretsClient
+	orgClient     *github.OrganizationClient
+	registry      *Registry
+	templateStore *template.Store
+	upgrades      *upgradeJobs
+	directCommits *DirectCommitPolicy
 }
 
-// NewService creates a new workflow service
-func NewService() *Service {
+// NewService creates a new workflow service. directCommits decides which
+// repositories may receive workflow changes without a pull request.
+func NewService(templateStore *template.Store, directCommits *DirectCommitPolicy) *Service {
+	registry := NewRegistry()
+	registerBuiltins(registry, templateStore)
+
 	return &Service{
-		githubClient: github.NewWorkflowClient(),
-		ec2Template:  template.NewEC2Generator(),
-		k8sTemplate:  template.NewKubernetesGenerator(),
+		githubClient:  github.NewWorkflowClient(),
+		secretsClient: github.NewSecretsClient(),
+		orgClient:     github.NewOrganizationClient(),
+		registry:      registry,
+		templateStore: templateStore,
+		upgrades:      newUpgradeJobs(),
+		directCommits: directCommits,
 	}
 }
 
-// GenerateWorkflow generates workflow YAML based on request
-func (s *Service) GenerateWorkflow(req *Request) (string, error) {
+// Registry returns the deployment type registry used by the service
+func (s *Service) Registry() *Registry {
+	return s.registry
+}
+
+// DeploymentTypes returns all registered deployment types
+func (s *Service) DeploymentTypes() []DeploymentDefinition {
+	return s.registry.List()
+}
+
+// Templates returns the loaded workflow templates and the store status
+func (s *Service) Templates() ([]template.Template, template.Status) {
+	return s.templateStore.List(), s.templateStore.Status()
+}
+
+// ReloadTemplates reloads workflow templates from the configured source
+func (s *Service) ReloadTemplates(ctx context.Context) error {
+	return s.templateStore.Load(ctx)
+}
+
+// ValidateRequest validates a workflow request against its deployment type
+// and normalizes its project paths
+func (s *Service) ValidateRequest(req *Request) error {
 	if !isValidWorkflowName(req.WorkflowName) {
-		return "", ErrInvalidWorkflowName
+		return ErrInvalidWorkflowName
 	}
-
-	if err := req.Validate(); err != nil {
-		return "", err
+	if err := normalizeProjectPaths(req); err != nil {
+		return err
 	}
+	if err := validateEnvironments(req); err != nil {
+		return err
+	}
+	if err := validateTriggers(req); err != nil {
+		return err
+	}
+	if err := validateDotEnvSecrets(req); err != nil {
+		return err
+	}
+	if err := validateConflictMode(req.OnConflict); err != nil {
+		return err
+	}
+	if err := validateAuxiliaryFiles(req); err != nil {
+		return err
+	}
+	if err := validatePullRequestOptions(req.PullRequest); err != nil {
+		return err
+	}
+	if err := validateDirectCommit(req.DirectCommit, req.PullRequest); err != nil {
+		return err
+	}
+	return s.registry.Validate(req)
+}
 
-	var yamlContent string
-	var err error
-
-	switch req.DeploymentType {
-	case DeploymentTypeEC2:
-		yamlContent, err = s.ec2Template.Generate(req)
-	case DeploymentTypeKubernetes:
-		yamlContent, err = s.k8sTemplate.Generate(req)
-	default:
-		return "", ErrInvalidDeploymentType
+// GenerateWorkflow generates workflow YAML based on request.
+// Generated YAML that fails structural validation is returned together with a *ValidationError.
+func (s *Service) GenerateWorkflow(req *Request) (string, error) {
+	if err := s.ValidateRequest(req); err != nil {
+		return "", err
 	}
 
+	def, _ := s.registry.Get(req.DeploymentType)
+	yamlContent, err := def.Generator.Generate(req)
 	if err != nil {
 		logger.Error().Err(err).Str("deployment_type", string(req.DeploymentType)).Msg("Failed to generate workflow")
 		return "", fmt.Errorf("%w: %v", ErrTemplateGenerationFailed, err)
 	}
 
+	// Embed the request so the workflow can be reopened and regenerated
+	yamlContent, err = embedConfigHeader(req, s.templateStore.Version(), yamlContent)
+	if err != nil {
+		return "", err
+	}
+
+	if diagnostics := ValidateWorkflowYAML(yamlContent); HasErrors(diagnostics) {
+		logger.Error().
+			Str("deployment_type", string(req.DeploymentType)).
+			Int("diagnostic_count", len(diagnostics)).
+			Msg("Generated workflow failed validation")
+		return yamlContent, &ValidationError{Cause: ErrInvalidYAMLGenerated, Diagnostics: diagnostics}
+	}
+
 	return yamlContent, nil
 }
 
-// CreateWorkflow creates a workflow in GitHub repository
-func (s *Service) CreateWorkflow(ctx context.Context, token, owner, repo, workflowName, content string) (*Response, error) {
+// PreviewWorkflow generates workflow YAML and reports validation diagnostics without failing on them
+func (s *Service) PreviewWorkflow(req *Request) (*PreviewResponse, error) {
+	yamlContent, err := s.GenerateWorkflow(req)
+
+	var validationErr *ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	if validationErr != nil {
+		diagnostics = validationErr.Diagnostics
+	} else {
+		diagnostics = ValidateWorkflowYAML(yamlContent)
+	}
+
+	return &PreviewResponse{
+		WorkflowName:   req.WorkflowName,
+		DeploymentType: req.DeploymentType,
+		YAMLContent:    yamlContent,
+		Valid:          !HasErrors(diagnostics),
+		Diagnostics:    diagnostics,
+		Secrets:        req.DotEnvSecrets(),
+	}, nil
+}
+
+// CreateWorkflow creates the workflow of a validated request in its GitHub
+// repository. The workflow file and the auxiliary files are written in a single
+// commit on a new branch, which only appears once the commit exists.
+func (s *Service) CreateWorkflow(ctx context.Context, token string, req *Request, content string, auxiliary []AuxiliaryFile) (*Response, error) {
+	owner, repo, workflowName := req.Owner, req.Repository, req.WorkflowName
 	if err := s.githubClient.VerifyRepository(ctx, token, owner, repo); err != nil {
 		return nil, err
 	}
@@ -68,29 +164,59 @@ func (s *Service) CreateWorkflow(ctx context.Context, token, owner, repo, workfl
 	if err != nil {
 		return nil, fmt.Errorf("failed to get default branch: %w", err)
 	}
+	if req.DirectCommit != nil {
+		return s.commitWorkflow(ctx, token, req, content, auxiliary, defaultBranch)
+	}
+	baseBranch := req.PullRequest.baseBranch(defaultBranch)
 
 	branchName := fmt.Sprintf("workflow/%s-%d", workflowName, time.Now().Unix())
 
-	baseSHA, err := s.githubClient.GetBranchSHA(ctx, token, owner, repo, defaultBranch)
+	baseSHA, err := s.githubClient.GetBranchSHA(ctx, token, owner, repo, baseBranch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get base branch SHA: %w", err)
 	}
 
-	if err := s.githubClient.CreateBranch(ctx, token, owner, repo, branchName, baseSHA); err != nil {
-		return nil, fmt.Errorf("failed to create branch: %w", err)
-	}
-
-	filePath := fmt.Sprintf(".github/workflows/%s.yml", workflowName)
+	filePath := WorkflowFilePath(workflowName)
 	message := fmt.Sprintf("Add workflow: %s", workflowName)
 
-	if err := s.githubClient.CreateFile(ctx, token, owner, repo, filePath, content, message, branchName); err != nil {
-		return nil, err
+	commit := s.githubClient.NewCommit(owner, repo).AddFile(filePath, content)
+	for _, file := range auxiliary {
+		commit.AddFile(file.Path, file.Content)
 	}
 
 	prTitle := fmt.Sprintf("Add workflow: %s", workflowName)
 	prBody := fmt.Sprintf("This PR adds the GitHub Actions workflow for `%s`.\n\nGenerated automatically by Calance Workflow Manager.", workflowName)
-	prURL, prNumber, err := s.githubClient.CreatePullRequest(ctx, token, owner, repo, branchName, defaultBranch, prTitle, prBody)
+	if len(auxiliary) > 0 {
+		prBody = fmt.Sprintf("This PR adds the GitHub Actions workflow for `%s` together with:\n\n- `%s`\n\nGenerated automatically by Calance Workflow Manager.",
+			workflowName, strings.Join(auxiliaryPaths(auxiliary), "`\n- `"))
+	}
+	prTitle, prBody, err = renderPullRequest(req.PullRequest, PullRequestTemplateData{
+		Action:       HistoryActionCreate,
+		Owner:        owner,
+		Repository:   repo,
+		WorkflowName: workflowName,
+		FilePath:     filePath,
+		Files:        commit.Files(),
+		Branch:       branchName,
+		BaseBranch:   baseBranch,
+		Request:      req,
+	}, prTitle, prBody)
+	if err != nil {
+		return nil, err
+	}
+
+	commitSHA, err := commit.Create(ctx, token, baseSHA, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	if err := s.githubClient.CreateBranch(ctx, token, owner, repo, branchName, commitSHA); err != nil {
+		return nil, fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	prURL, prNumber, warnings, err := s.openPullRequest(ctx, token, owner, repo, branchName, baseBranch, prTitle, prBody, req.PullRequest)
 	if err != nil {
+		s.rollbackBranch(ctx, token, owner, repo, branchName)
 		return nil, fmt.Errorf("failed to create pull request: %w", err)
 	}
 
@@ -100,7 +226,13 @@ func (s *Service) CreateWorkflow(ctx context.Context, token, owner, repo, workfl
 		WorkflowName: workflowName,
 		FilePath:     filePath,
 		FileURL:      prURL,
+		PRNumber:     prNumber,
+		Branch:       branchName,
+		BaseBranch:   baseBranch,
+		CommitSHA:    commitSHA,
+		Files:        commit.Files(),
 		Message:      fmt.Sprintf("Pull request #%d created for workflow '%s'", prNumber, workflowName),
+		Warnings:     warnings,
 	}, nil
 }
 
@@ -126,20 +258,39 @@ func (s *Service) GetWorkflows(ctx context.Context, token, owner, repo string) (
 	return files, nil
 }
 
+// WorkflowFilePath returns the path of the file a workflow is created in
+func WorkflowFilePath(workflowName string) string {
+	return fmt.Sprintf(".github/workflows/%s.yml", workflowName)
+}
+
+// WorkflowNameFromPath returns the workflow name of a workflow file path
+func WorkflowNameFromPath(filePath string) string {
+	parts := strings.Split(filePath, "/")
+	fileName := parts[len(parts)-1]
+	workflowName := strings.TrimSuffix(fileName, ".yml")
+	return strings.TrimSuffix(workflowName, ".yaml")
+}
+
 func isValidWorkflowName(name string) bool {
 	matched, _ := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, name)
 	return matched && len(name) > 0 && len(name) <= 255
 }
 
-// GetWorkflowContent retrieves the content of a workflow file
-func (s *Service) GetWorkflowContent(ctx context.Context, token, owner, repo, filePath string) (*FileContentResponse, error) {
-	// Validate that the file path is a workflow file
+// validateWorkflowPath checks that a path names a workflow file
+func validateWorkflowPath(filePath string) error {
 	if !strings.HasPrefix(filePath, ".github/workflows/") {
-		return nil, fmt.Errorf("invalid workflow file path: must be in .github/workflows/")
+		return fmt.Errorf("%w: must be in .github/workflows/", ErrInvalidWorkflowPath)
 	}
-
 	if !strings.HasSuffix(filePath, ".yml") && !strings.HasSuffix(filePath, ".yaml") {
-		return nil, fmt.Errorf("invalid workflow file: must be a .yml or .yaml file")
+		return fmt.Errorf("%w: must be a .yml or .yaml file", ErrInvalidWorkflowPath)
+	}
+	return nil
+}
+
+// GetWorkflowContent retrieves the content of a workflow file
+func (s *Service) GetWorkflowContent(ctx context.Context, token, owner, repo, filePath string) (*FileContentResponse, error) {
+	if err := validateWorkflowPath(filePath); err != nil {
+		return nil, err
 	}
 
 	// Fetch file content from GitHub
@@ -168,13 +319,20 @@ func (s *Service) GetWorkflowContent(ctx context.Context, token, owner, repo, fi
 
 // UpdateWorkflow updates an existing workflow file and creates a PR
 func (s *Service) UpdateWorkflow(ctx context.Context, token string, req *UpdateWorkflowRequest) (*Response, error) {
-	// Validate that the file path is a workflow file
-	if !strings.HasPrefix(req.FilePath, ".github/workflows/") {
-		return nil, fmt.Errorf("invalid workflow file path: must be in .github/workflows/")
+	if err := validateWorkflowPath(req.FilePath); err != nil {
+		return nil, err
+	}
+
+	if err := validatePullRequestOptions(req.PullRequest); err != nil {
+		return nil, err
+	}
+	if err := validateDirectCommit(req.DirectCommit, req.PullRequest); err != nil {
+		return nil, err
 	}
 
-	if !strings.HasSuffix(req.FilePath, ".yml") && !strings.HasSuffix(req.FilePath, ".yaml") {
-		return nil, fmt.Errorf("invalid workflow file: must be a .yml or .yaml file")
+	// Validate the edited workflow before touching the repository
+	if diagnostics := ValidateWorkflowYAML(req.Content); HasErrors(diagnostics) {
+		return nil, &ValidationError{Cause: ErrInvalidWorkflowYAML, Diagnostics: diagnostics}
 	}
 
 	// Verify repository exists
@@ -188,16 +346,59 @@ func (s *Service) UpdateWorkflow(ctx context.Context, token string, req *UpdateW
 		return nil, fmt.Errorf("failed to get default branch: %w", err)
 	}
 
+	baseBranch := req.PullRequest.baseBranch(defaultBranch)
+
 	// Extract workflow name from file path
-	parts := strings.Split(req.FilePath, "/")
-	fileName := parts[len(parts)-1]
-	workflowName := strings.TrimSuffix(fileName, ".yml")
-	workflowName = strings.TrimSuffix(workflowName, ".yaml")
+	workflowName := WorkflowNameFromPath(req.FilePath)
+
+	// Set default commit message if not provided
+	message := req.CommitMessage
+	if message == "" {
+		message = fmt.Sprintf("Update workflow: %s", workflowName)
+	}
+
+	if req.DirectCommit != nil {
+		branch, err := s.directCommitBranch(ctx, token, req.Owner, req.Repository, req.DirectCommit, defaultBranch)
+		if err != nil {
+			return nil, err
+		}
+		commitSHA, err := s.githubClient.UpdateFile(ctx, token, req.Owner, req.Repository, req.FilePath, req.Content, message, branch, req.SHA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update file: %w", err)
+		}
+		return &Response{
+			Owner:        req.Owner,
+			Repository:   req.Repository,
+			WorkflowName: workflowName,
+			FilePath:     req.FilePath,
+			Branch:       branch,
+			CommitSHA:    commitSHA,
+			Message:      fmt.Sprintf("Workflow '%s' update committed to branch '%s'", workflowName, branch),
+		}, nil
+	}
 
 	// Create a new branch for the update
 	branchName := fmt.Sprintf("update-workflow/%s-%d", workflowName, time.Now().Unix())
 
-	baseSHA, err := s.githubClient.GetBranchSHA(ctx, token, req.Owner, req.Repository, defaultBranch)
+	// Create pull request content before touching the repository
+	prTitle := fmt.Sprintf("Update workflow: %s", workflowName)
+	prBody := fmt.Sprintf("This PR updates the GitHub Actions workflow `%s`.\n\nUpdated automatically by Calance Workflow Manager.", workflowName)
+	prTitle, prBody, err = renderPullRequest(req.PullRequest, PullRequestTemplateData{
+		Action:       HistoryActionUpdate,
+		Owner:        req.Owner,
+		Repository:   req.Repository,
+		WorkflowName: workflowName,
+		FilePath:     req.FilePath,
+		Files:        []string{req.FilePath},
+		Branch:       branchName,
+		BaseBranch:   baseBranch,
+		Request:      req.Request,
+	}, prTitle, prBody)
+	if err != nil {
+		return nil, err
+	}
+
+	baseSHA, err := s.githubClient.GetBranchSHA(ctx, token, req.Owner, req.Repository, baseBranch)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get base branch SHA: %w", err)
 	}
@@ -206,22 +407,17 @@ func (s *Service) UpdateWorkflow(ctx context.Context, token string, req *UpdateW
 		return nil, fmt.Errorf("failed to create branch: %w", err)
 	}
 
-	// Set default commit message if not provided
-	message := req.CommitMessage
-	if message == "" {
-		message = fmt.Sprintf("Update workflow: %s", workflowName)
-	}
-
 	// Update the file on the new branch
-	if err := s.githubClient.UpdateFile(ctx, token, req.Owner, req.Repository, req.FilePath, req.Content, message, branchName, req.SHA); err != nil {
+	commitSHA, err := s.githubClient.UpdateFile(ctx, token, req.Owner, req.Repository, req.FilePath, req.Content, message, branchName, req.SHA)
+	if err != nil {
+		s.rollbackBranch(ctx, token, req.Owner, req.Repository, branchName)
 		return nil, fmt.Errorf("failed to update file: %w", err)
 	}
 
 	// Create pull request
-	prTitle := fmt.Sprintf("Update workflow: %s", workflowName)
-	prBody := fmt.Sprintf("This PR updates the GitHub Actions workflow `%s`.\n\nUpdated automatically by Calance Workflow Manager.", workflowName)
-	prURL, prNumber, err := s.githubClient.CreatePullRequest(ctx, token, req.Owner, req.Repository, branchName, defaultBranch, prTitle, prBody)
+	prURL, prNumber, warnings, err := s.openPullRequest(ctx, token, req.Owner, req.Repository, branchName, baseBranch, prTitle, prBody, req.PullRequest)
 	if err != nil {
+		s.rollbackBranch(ctx, token, req.Owner, req.Repository, branchName)
 		return nil, fmt.Errorf("failed to create pull request: %w", err)
 	}
 
@@ -231,6 +427,11 @@ func (s *Service) UpdateWorkflow(ctx context.Context, token string, req *UpdateW
 		WorkflowName: workflowName,
 		FilePath:     req.FilePath,
 		FileURL:      prURL,
+		PRNumber:     prNumber,
+		Branch:       branchName,
+		BaseBranch:   baseBranch,
+		CommitSHA:    commitSHA,
 		Message:      fmt.Sprintf("Pull request #%d created for workflow '%s' update", prNumber, workflowName),
+		Warnings:     warnings,
 	}, nil
 }
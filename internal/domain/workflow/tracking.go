@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+)
+
+// failedConclusions are check run conclusions that fail the checks of a pull request
+var failedConclusions = map[string]bool{
+	"failure":         true,
+	"timed_out":       true,
+	"cancelled":       true,
+	"action_required": true,
+	"startup_failure": true,
+}
+
+// NewTrackedPullRequest starts tracking the pull request of a workflow change
+// made by userID. It returns nil when the change did not open a pull request.
+func NewTrackedPullRequest(userID uuid.UUID, action HistoryAction, response *Response) *TrackedPullRequest {
+	if response == nil || response.PRNumber == 0 {
+		return nil
+	}
+	return &TrackedPullRequest{
+		UserID:       userID,
+		Action:       action,
+		Owner:        response.Owner,
+		Repository:   response.Repository,
+		Number:       response.PRNumber,
+		WorkflowName: response.WorkflowName,
+		FilePath:     response.FilePath,
+		URL:          response.FileURL,
+		HeadBranch:   response.Branch,
+		BaseBranch:   response.BaseBranch,
+		HeadSHA:      response.CommitSHA,
+		State:        PullRequestStateOpen,
+		ChecksStatus: ChecksStatusPending,
+		ReviewState:  ReviewStatePending,
+	}
+}
+
+// RefreshPullRequest updates a tracked pull request with its state, checks and
+// reviews on GitHub. Checks and reviews of merged or closed pull requests are
+// kept as they were last seen.
+func (s *Service) RefreshPullRequest(ctx context.Context, token string, pr *TrackedPullRequest) error {
+	pull, err := s.githubClient.GetPullRequest(ctx, token, pr.Owner, pr.Repository, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request #%d: %w", pr.Number, err)
+	}
+
+	pr.Title = pull.Title
+	pr.URL = pull.HTMLURL
+	pr.Draft = pull.Draft
+	pr.HeadBranch = pull.Head.Ref
+	pr.HeadSHA = pull.Head.SHA
+	pr.BaseBranch = pull.Base.Ref
+	pr.MergedAt = pull.MergedAt
+	pr.ClosedAt = pull.ClosedAt
+	switch {
+	case pull.Merged || pull.MergedAt != nil:
+		pr.State = PullRequestStateMerged
+	case pull.State == "closed":
+		pr.State = PullRequestStateClosed
+	default:
+		pr.State = PullRequestStateOpen
+	}
+
+	if pr.State == PullRequestStateOpen {
+		runs, err := s.githubClient.ListCheckRuns(ctx, token, pr.Owner, pr.Repository, pr.HeadSHA)
+		if err != nil {
+			return fmt.Errorf("failed to list check runs: %w", err)
+		}
+		status, err := s.githubClient.GetCombinedStatus(ctx, token, pr.Owner, pr.Repository, pr.HeadSHA)
+		if err != nil {
+			return fmt.Errorf("failed to get commit status: %w", err)
+		}
+		pr.ChecksStatus = checksStatus(runs, status)
+
+		reviews, err := s.githubClient.ListPullRequestReviews(ctx, token, pr.Owner, pr.Repository, pr.Number)
+		if err != nil {
+			return fmt.Errorf("failed to list reviews: %w", err)
+		}
+		pr.ReviewState = reviewState(reviews)
+	}
+
+	now := time.Now()
+	pr.RefreshedAt = &now
+	return nil
+}
+
+// checksStatus combines the check runs and commit statuses of a commit. Any
+// failure fails the checks; otherwise any unfinished check keeps them pending.
+func checksStatus(runs []github.CheckRun, status *github.CombinedStatus) string {
+	pending := false
+	for _, run := range runs {
+		if run.Status != "completed" {
+			pending = true
+			continue
+		}
+		if failedConclusions[run.Conclusion] {
+			return ChecksStatusFailure
+		}
+	}
+
+	hasStatuses := status != nil && status.TotalCount > 0
+	if hasStatuses {
+		switch status.State {
+		case "failure", "error":
+			return ChecksStatusFailure
+		case "pending":
+			pending = true
+		}
+	}
+
+	switch {
+	case pending:
+		return ChecksStatusPending
+	case len(runs) == 0 && !hasStatuses:
+		return ChecksStatusNone
+	default:
+		return ChecksStatusSuccess
+	}
+}
+
+// reviewState derives the review state of a pull request from the latest
+// approving, change-requesting or dismissed review of every reviewer
+func reviewState(reviews []github.Review) string {
+	latest := make(map[string]string)
+	for _, review := range reviews {
+		switch review.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[review.User.Login] = review.State
+		}
+	}
+
+	state := ReviewStatePending
+	for _, reviewState := range latest {
+		switch reviewState {
+		case "CHANGES_REQUESTED":
+			return ReviewStateChangesRequested
+		case "APPROVED":
+			state = ReviewStateApproved
+		}
+	}
+	return state
+}
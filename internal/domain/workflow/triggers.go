@@ -0,0 +1,234 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/filterpattern"
+)
+
+var dispatchInputNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// pullRequestTypes are the pull_request activity types GitHub accepts
+var pullRequestTypes = keySet(
+	"assigned", "unassigned", "labeled", "unlabeled", "opened", "edited", "closed", "reopened",
+	"synchronize", "converted_to_draft", "ready_for_review", "locked", "unlocked",
+	"review_requested", "review_request_removed", "auto_merge_enabled", "auto_merge_disabled",
+	"milestoned", "demilestoned", "enqueued", "dequeued",
+)
+
+// validateTriggers checks the trigger filters, cron expressions and dispatch inputs of a request
+func validateTriggers(req *Request) error {
+	t := req.Triggers
+	if t == nil {
+		return nil
+	}
+
+	if err := validateFilterPatterns("tags", t.Tags); err != nil {
+		return err
+	}
+	if err := validateFilterPatterns("branches", t.Branches); err != nil {
+		return err
+	}
+
+	if t.PullRequest != nil {
+		if err := validateFilterPatterns("pullRequest.branches", t.PullRequest.Branches); err != nil {
+			return err
+		}
+		for _, activity := range t.PullRequest.Types {
+			if !pullRequestTypes[activity] {
+				return fmt.Errorf("%w: unknown pull request type '%s'", ErrInvalidTrigger, activity)
+			}
+		}
+	}
+
+	for _, schedule := range t.Schedules {
+		if err := validateCron(schedule); err != nil {
+			return fmt.Errorf("%w: schedule '%s' %v", ErrInvalidTrigger, schedule, err)
+		}
+	}
+
+	if t.WorkflowDispatch != nil {
+		if err := validateDispatchInputs(t.WorkflowDispatch.Inputs, req.Stages()); err != nil {
+			return err
+		}
+	}
+
+	if t.Environment != "" && !hasStage(req.Stages(), t.Environment) {
+		return fmt.Errorf("%w: environment '%s' is not defined", ErrInvalidTrigger, t.Environment)
+	}
+
+	hasEvent := len(req.TagTriggers()) > 0 || len(t.Branches) > 0 || t.PullRequest != nil ||
+		t.WorkflowDispatch != nil || len(t.Schedules) > 0
+	if !hasEvent {
+		return fmt.Errorf("%w: at least one trigger is required", ErrInvalidTrigger)
+	}
+	return nil
+}
+
+// validateFilterPatterns checks a branch or tag filter list. Like GitHub, a
+// list with negated patterns must also contain a positive one.
+func validateFilterPatterns(field string, patterns []string) error {
+	positive := false
+	for _, p := range patterns {
+		parsed, err := filterpattern.Parse(p)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidTrigger, field, err)
+		}
+		if !parsed.Negated {
+			positive = true
+		}
+	}
+	if len(patterns) > 0 && !positive {
+		return fmt.Errorf("%w: %s must contain at least one pattern without '!'", ErrInvalidTrigger, field)
+	}
+	return nil
+}
+
+func validateDispatchInputs(inputs []DispatchInput, stages []Environment) error {
+	seen := make(map[string]bool)
+	for _, input := range inputs {
+		if !dispatchInputNamePattern.MatchString(input.Name) {
+			return fmt.Errorf("%w: input name '%s' must start with a letter or '_' and contain only alphanumeric characters, '-' and '_'", ErrInvalidTrigger, input.Name)
+		}
+		if seen[input.Name] {
+			return fmt.Errorf("%w: input '%s' is defined more than once", ErrInvalidTrigger, input.Name)
+		}
+		seen[input.Name] = true
+
+		switch input.Type {
+		case DispatchInputString, DispatchInputEnvironment:
+		case DispatchInputBoolean:
+			if input.Default != "" && input.Default != "true" && input.Default != "false" {
+				return fmt.Errorf("%w: input '%s' default must be true or false", ErrInvalidTrigger, input.Name)
+			}
+		case DispatchInputNumber:
+			if input.Default != "" {
+				if _, err := strconv.ParseFloat(input.Default, 64); err != nil {
+					return fmt.Errorf("%w: input '%s' default must be a number", ErrInvalidTrigger, input.Name)
+				}
+			}
+		case DispatchInputChoice:
+			if len(input.Options) == 0 {
+				return fmt.Errorf("%w: choice input '%s' requires options", ErrInvalidTrigger, input.Name)
+			}
+			if input.Default != "" && !containsString(input.Options, input.Default) {
+				return fmt.Errorf("%w: input '%s' default must be one of its options", ErrInvalidTrigger, input.Name)
+			}
+		default:
+			return fmt.Errorf("%w: input '%s' has unsupported type '%s'", ErrInvalidTrigger, input.Name, input.Type)
+		}
+		if len(input.Options) > 0 && input.Type != DispatchInputChoice {
+			return fmt.Errorf("%w: only choice inputs can have options", ErrInvalidTrigger)
+		}
+
+		// The "environment" input selects the deployment stage of manual runs
+		if input.Name == "environment" && input.Type == DispatchInputChoice {
+			for _, option := range input.Options {
+				if !hasStage(stages, option) {
+					return fmt.Errorf("%w: environment input option '%s' is not a defined environment", ErrInvalidTrigger, option)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func hasStage(stages []Environment, name string) bool {
+	for _, env := range stages {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// cronField describes the range and names accepted by one cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 6, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// validateCron checks a five-field POSIX cron expression as accepted by GitHub
+// Actions schedules: '*', values, ranges, lists, steps and month/day names
+func validateCron(expr string) error {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return fmt.Errorf("must have %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	for i, field := range fields {
+		spec := cronFields[i]
+		for _, part := range strings.Split(field, ",") {
+			if err := spec.validatePart(part); err != nil {
+				return fmt.Errorf("%s field '%s' %v", spec.name, field, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f cronField) validatePart(part string) error {
+	rangePart, step, hasStep := strings.Cut(part, "/")
+	if hasStep {
+		n, err := strconv.Atoi(step)
+		if err != nil || n < 1 || n > f.max {
+			return fmt.Errorf("has an invalid step '%s'", step)
+		}
+	}
+
+	if rangePart == "*" {
+		return nil
+	}
+
+	low, high, isRange := strings.Cut(rangePart, "-")
+	lowValue, err := f.value(low)
+	if err != nil {
+		return err
+	}
+	if !isRange {
+		return nil
+	}
+	highValue, err := f.value(high)
+	if err != nil {
+		return err
+	}
+	if lowValue > highValue {
+		return fmt.Errorf("has a reversed range '%s'", rangePart)
+	}
+	return nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return i + f.min, nil
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("has value '%s' outside %d-%d", s, f.min, f.max)
+	}
+	return n, nil
+}
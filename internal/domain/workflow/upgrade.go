@@ -0,0 +1,372 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// Upgrade job statuses
+const (
+	UpgradeStatusRunning   = "running"
+	UpgradeStatusCompleted = "completed"
+	UpgradeStatusFailed    = "failed"
+)
+
+// Upgrade outcomes of a repository
+const (
+	UpgradeResultUpgraded = "upgraded"
+	UpgradeResultUpToDate = "up_to_date"
+	UpgradeResultFailed   = "failed"
+)
+
+var (
+	// sharedWorkflowRefPattern matches the ref of a reusable workflow from the
+	// calance-workflows repository, capturing everything before the ref
+	sharedWorkflowRefPattern = regexp.MustCompile(`(?m)^(\s*(?:-\s+)?uses:\s*["']?[\w.-]+/calance-workflows/[^@\s"']+@)([^\s"'#]+)`)
+	workflowsReleasePattern  = regexp.MustCompile(`(?m)^(\s*workflows_release:[ \t]*)("[^"\n]*"|'[^'\n]*'|[^\s#]+)`)
+)
+
+// UpgradeRequest represents a bulk upgrade of generated workflows to a new
+// release of the shared workflows
+type UpgradeRequest struct {
+	Organization string `json:"organization" binding:"required"`
+	ReleaseTag   string `json:"releaseTag" binding:"required"`
+	// Repositories limits the upgrade to the named repositories of the organization
+	Repositories []string `json:"repositories,omitempty"`
+}
+
+// UpgradeJob reports the progress of a bulk upgrade started by a user
+type UpgradeJob struct {
+	ID                    uuid.UUID       `json:"id"`
+	UserID                uuid.UUID       `json:"userId"`
+	Organization          string          `json:"organization"`
+	ReleaseTag            string          `json:"releaseTag"`
+	Status                string          `json:"status"`
+	TotalRepositories     int             `json:"totalRepositories"`
+	ProcessedRepositories int             `json:"processedRepositories"`
+	Results               []UpgradeResult `json:"results"`
+	Error                 string          `json:"error,omitempty"`
+	CreatedAt             time.Time       `json:"createdAt"`
+	FinishedAt            *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// UpgradeResult is the outcome of upgrading the generated workflows of one repository
+type UpgradeResult struct {
+	Repository     string   `json:"repository"`
+	Status         string   `json:"status"`
+	Files          []string `json:"files,omitempty"`
+	PullRequestURL string   `json:"pullRequestUrl,omitempty"`
+	PRNumber       int      `json:"prNumber,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// UpgradeRecorder is called with the pull request of each repository an
+// upgrade changed, or with the error of each repository it failed to upgrade
+type UpgradeRecorder func(response *Response, err error)
+
+// upgradeJobTTL is how long finished upgrade jobs stay available
+const upgradeJobTTL = 24 * time.Hour
+
+// upgradeJobs keeps bulk upgrade jobs in memory; they do not survive a restart
+type upgradeJobs struct {
+	mu   sync.RWMutex
+	jobs map[uuid.UUID]*UpgradeJob
+}
+
+func newUpgradeJobs() *upgradeJobs {
+	return &upgradeJobs{jobs: make(map[uuid.UUID]*UpgradeJob)}
+}
+
+// add tracks a job and evicts the jobs that finished more than upgradeJobTTL ago
+func (j *upgradeJobs) add(job *UpgradeJob) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	cutoff := time.Now().Add(-upgradeJobTTL)
+	for id, existing := range j.jobs {
+		if existing.FinishedAt != nil && existing.FinishedAt.Before(cutoff) {
+			delete(j.jobs, id)
+		}
+	}
+	j.jobs[job.ID] = job
+}
+
+// get returns a copy of a job that is safe to use while the job runs
+func (j *upgradeJobs) get(id uuid.UUID) (*UpgradeJob, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	job, ok := j.jobs[id]
+	if !ok || (job.FinishedAt != nil && time.Since(*job.FinishedAt) > upgradeJobTTL) {
+		return nil, false
+	}
+	snapshot := *job
+	snapshot.Results = append([]UpgradeResult{}, job.Results...)
+	return &snapshot, true
+}
+
+func (j *upgradeJobs) update(id uuid.UUID, fn func(job *UpgradeJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if job, ok := j.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// StartUpgrade starts upgrading the generated workflows of an organization in
+// the background for a user and returns the job tracking it. record, if not
+// nil, is called from the background job as each repository finishes.
+func (s *Service) StartUpgrade(token string, userID uuid.UUID, req *UpgradeRequest, record UpgradeRecorder) (*UpgradeJob, error) {
+	if !isValidReleaseTag(req.ReleaseTag) {
+		return nil, ErrInvalidReleaseTag
+	}
+
+	job := &UpgradeJob{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Organization: req.Organization,
+		ReleaseTag:   req.ReleaseTag,
+		Status:       UpgradeStatusRunning,
+		Results:      []UpgradeResult{},
+		CreatedAt:    time.Now(),
+	}
+	s.upgrades.add(job)
+
+	// The job outlives the HTTP request that started it
+	go s.runUpgrade(context.Background(), token, job.ID, *req, record)
+
+	snapshot, _ := s.upgrades.get(job.ID)
+	return snapshot, nil
+}
+
+// GetUpgradeJob returns the current state of a bulk upgrade
+func (s *Service) GetUpgradeJob(id uuid.UUID) (*UpgradeJob, error) {
+	job, ok := s.upgrades.get(id)
+	if !ok {
+		return nil, ErrUpgradeJobNotFound
+	}
+	return job, nil
+}
+
+// runUpgrade upgrades the repositories one at a time, which keeps the job
+// clear of GitHub's secondary rate limits on content creation
+func (s *Service) runUpgrade(ctx context.Context, token string, id uuid.UUID, req UpgradeRequest, record UpgradeRecorder) {
+	repos, err := s.orgClient.GetOrganizationRepositories(ctx, token, req.Organization)
+	if err != nil {
+		logger.Error().Err(err).Str("organization", req.Organization).Msg("Failed to list repositories for upgrade")
+		s.finishUpgrade(id, fmt.Errorf("failed to list repositories: %w", err))
+		return
+	}
+
+	var names []string
+	for _, repo := range repos {
+		if repo.Archived {
+			continue
+		}
+		if len(req.Repositories) > 0 && !containsString(req.Repositories, repo.Name) {
+			continue
+		}
+		names = append(names, repo.Name)
+	}
+	s.upgrades.update(id, func(job *UpgradeJob) {
+		job.TotalRepositories = len(names)
+	})
+
+	for _, repo := range names {
+		result, response := s.upgradeRepository(ctx, token, req.Organization, repo, req.ReleaseTag)
+		if record != nil && response != nil {
+			var err error
+			if result.Status == UpgradeResultFailed {
+				err = errors.New(result.Error)
+			}
+			record(response, err)
+		}
+		s.upgrades.update(id, func(job *UpgradeJob) {
+			job.ProcessedRepositories++
+			if result != nil {
+				job.Results = append(job.Results, *result)
+			}
+		})
+	}
+
+	logger.Info().
+		Str("organization", req.Organization).
+		Str("release_tag", req.ReleaseTag).
+		Int("repositories", len(names)).
+		Msg("Workflow upgrade finished")
+	s.finishUpgrade(id, nil)
+}
+
+func (s *Service) finishUpgrade(id uuid.UUID, err error) {
+	now := time.Now()
+	s.upgrades.update(id, func(job *UpgradeJob) {
+		job.Status = UpgradeStatusCompleted
+		if err != nil {
+			job.Status = UpgradeStatusFailed
+			job.Error = err.Error()
+		}
+		job.FinishedAt = &now
+	})
+}
+
+// upgradeRepository rewrites the shared workflow refs of every generated
+// workflow in a repository and opens a single pull request with the changes.
+// The response describes the pull request, or the repository of a failed
+// upgrade, and is nil when there was nothing to upgrade. Both are nil for
+// repositories without generated workflows.
+func (s *Service) upgradeRepository(ctx context.Context, token, owner, repo, releaseTag string) (*UpgradeResult, *Response) {
+	result := &UpgradeResult{Repository: repo}
+	response := &Response{Owner: owner, Repository: repo}
+	fail := func(err error) (*UpgradeResult, *Response) {
+		logger.Error().Err(err).Str("owner", owner).Str("repo", repo).Msg("Failed to upgrade workflows")
+		result.Status = UpgradeResultFailed
+		result.Error = err.Error()
+		return result, response
+	}
+
+	files, err := s.githubClient.GetWorkflowFiles(ctx, token, owner, repo)
+	if err != nil {
+		return fail(fmt.Errorf("failed to list workflows: %w", err))
+	}
+
+	type change struct {
+		path, sha, content string
+	}
+	var changes []change
+	var names []string
+	generated := false
+	for _, file := range files {
+		content, sha, err := s.githubClient.GetFileContent(ctx, token, owner, repo, file.Path)
+		if err != nil {
+			return fail(fmt.Errorf("failed to fetch %s: %w", file.Path, err))
+		}
+		if !isGeneratedWorkflow(content) {
+			continue
+		}
+		generated = true
+
+		upgraded, err := upgradeReleaseTag(content, releaseTag)
+		if err != nil {
+			return fail(fmt.Errorf("failed to upgrade %s: %w", file.Path, err))
+		}
+		if upgraded != content {
+			changes = append(changes, change{path: file.Path, sha: sha, content: upgraded})
+			result.Files = append(result.Files, file.Path)
+			names = append(names, strings.TrimSuffix(path.Base(file.Path), path.Ext(file.Path)))
+		}
+	}
+
+	if !generated {
+		return nil, nil
+	}
+	if len(changes) == 0 {
+		result.Status = UpgradeResultUpToDate
+		return result, nil
+	}
+	response.WorkflowName = strings.Join(names, ",")
+	response.Files = result.Files
+
+	defaultBranch, err := s.githubClient.GetDefaultBranch(ctx, token, owner, repo)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get default branch: %w", err))
+	}
+	baseSHA, err := s.githubClient.GetBranchSHA(ctx, token, owner, repo, defaultBranch)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get base branch SHA: %w", err))
+	}
+
+	branchName := fmt.Sprintf("workflow-upgrade/%s-%d", releaseTag, time.Now().Unix())
+	if err := s.githubClient.CreateBranch(ctx, token, owner, repo, branchName, baseSHA); err != nil {
+		return fail(fmt.Errorf("failed to create branch: %w", err))
+	}
+
+	message := fmt.Sprintf("Upgrade shared workflows to %s", releaseTag)
+	var commitSHA string
+	for _, c := range changes {
+		if commitSHA, err = s.githubClient.UpdateFile(ctx, token, owner, repo, c.path, c.content, message, branchName, c.sha); err != nil {
+			s.rollbackBranch(ctx, token, owner, repo, branchName)
+			return fail(fmt.Errorf("failed to update %s: %w", c.path, err))
+		}
+	}
+
+	prBody := fmt.Sprintf("This PR upgrades the shared Calance workflows to `%s` in:\n\n- `%s`\n\nGenerated automatically by Calance Workflow Manager.",
+		releaseTag, strings.Join(result.Files, "`\n- `"))
+	prURL, prNumber, err := s.githubClient.CreatePullRequest(ctx, token, owner, repo, branchName, defaultBranch, message, prBody, false)
+	if err != nil {
+		s.rollbackBranch(ctx, token, owner, repo, branchName)
+		return fail(fmt.Errorf("failed to create pull request: %w", err))
+	}
+
+	result.Status = UpgradeResultUpgraded
+	result.PullRequestURL = prURL
+	result.PRNumber = prNumber
+
+	response.FilePath = result.Files[0]
+	response.FileURL = prURL
+	response.PRNumber = prNumber
+	response.Branch = branchName
+	response.BaseBranch = defaultBranch
+	response.CommitSHA = commitSHA
+	response.Message = message
+	response.CreatedAt = time.Now()
+	return result, response
+}
+
+// isGeneratedWorkflow reports whether a workflow file was generated by this
+// service, either with an embedded config or by calling the shared workflows
+func isGeneratedWorkflow(content string) bool {
+	if _, err := ParseConfigHeader(content); err == nil {
+		return true
+	}
+	return sharedWorkflowRefPattern.MatchString(content)
+}
+
+// upgradeReleaseTag points the shared workflow refs, the workflows_release
+// inputs and the embedded config of a generated workflow at a new release. The
+// checksum is only refreshed for files without manual changes, so those still
+// show up as drifted afterwards.
+func upgradeReleaseTag(content, releaseTag string) (string, error) {
+	unmodified := false
+	if config, body, err := readConfigHeader(content); err == nil {
+		unmodified = config.Checksum == contentChecksum(body)
+	}
+
+	upgraded := sharedWorkflowRefPattern.ReplaceAllStringFunc(content, func(match string) string {
+		return sharedWorkflowRefPattern.FindStringSubmatch(match)[1] + releaseTag
+	})
+	upgraded = workflowsReleasePattern.ReplaceAllStringFunc(upgraded, func(match string) string {
+		return workflowsReleasePattern.FindStringSubmatch(match)[1] + `"` + releaseTag + `"`
+	})
+
+	rewritten, err := rewriteConfigHeader(upgraded, func(config *embeddedConfig, body string) {
+		config.Request.SetReleaseTag(releaseTag)
+		if unmodified {
+			config.Checksum = contentChecksum(body)
+		}
+	})
+	if errors.Is(err, ErrWorkflowConfigNotFound) {
+		return upgraded, nil
+	}
+	return rewritten, err
+}
+
+// SetReleaseTag sets the release of the shared workflows the request pins
+func (r *Request) SetReleaseTag(releaseTag string) {
+	if r.EC2CommonFields != nil {
+		r.EC2CommonFields.ReleaseTag = releaseTag
+	}
+	if r.KubernetesCommonFields != nil {
+		r.KubernetesCommonFields.ReleaseTag = releaseTag
+	}
+	if _, ok := r.Config["releaseTag"]; ok {
+		r.Config["releaseTag"] = releaseTag
+	}
+}
@@ -0,0 +1,658 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+)
+
+// Severity represents how serious a diagnostic is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic describes a problem found in a workflow file
+type Diagnostic struct {
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// ValidationError is returned when a workflow file fails structural validation
+type ValidationError struct {
+	Cause       error
+	Diagnostics []Diagnostic
+}
+
+// Error returns the first error diagnostic and how many others were found
+func (e *ValidationError) Error() string {
+	var errs []Diagnostic
+	for _, d := range e.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	if len(errs) == 0 {
+		return e.Cause.Error()
+	}
+
+	msg := fmt.Sprintf("%s: line %d: %s", e.Cause, errs[0].Line, errs[0].Message)
+	if len(errs) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(errs)-1)
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error describing what was validated
+func (e *ValidationError) Unwrap() error {
+	return e.Cause
+}
+
+// HasErrors reports whether any diagnostic is an error
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	jobIDPattern            = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	reusableWorkflowPattern = regexp.MustCompile(`^([^/\s]+/[^/\s]+/\.github/workflows/[^@\s]+\.ya?ml@[^\s]+|\./\.github/workflows/[^@\s]+\.ya?ml)$`)
+	stepActionPattern       = regexp.MustCompile(`^([^/\s]+/[^@\s]+@[^\s]+|\./[^\s]*|docker://[^\s]+)$`)
+	matrixRefPattern        = regexp.MustCompile(`matrix\.([A-Za-z0-9_-]+)`)
+	expressionPattern       = regexp.MustCompile(`^\s*\$\{\{.*\}\}\s*$`)
+)
+
+var workflowKeys = keySet("name", "run-name", "on", "permissions", "env", "defaults", "concurrency", "jobs")
+
+var jobKeys = keySet(
+	"name", "needs", "permissions", "runs-on", "environment", "concurrency", "outputs", "env",
+	"defaults", "if", "steps", "timeout-minutes", "strategy", "continue-on-error", "container",
+	"services", "uses", "with", "secrets",
+)
+
+var reusableJobKeys = keySet("name", "uses", "with", "secrets", "strategy", "needs", "if", "concurrency", "permissions")
+
+var strategyKeys = keySet("matrix", "fail-fast", "max-parallel")
+
+var stepKeys = keySet("id", "if", "name", "uses", "run", "shell", "with", "env", "working-directory", "continue-on-error", "timeout-minutes")
+
+var workflowEvents = keySet(
+	"branch_protection_rule", "check_run", "check_suite", "create", "delete", "deployment",
+	"deployment_status", "discussion", "discussion_comment", "fork", "gollum", "issue_comment",
+	"issues", "label", "merge_group", "milestone", "page_build", "public", "pull_request",
+	"pull_request_review", "pull_request_review_comment", "pull_request_target", "push",
+	"registry_package", "release", "repository_dispatch", "schedule", "status", "watch",
+	"workflow_call", "workflow_dispatch", "workflow_run",
+)
+
+// ValidateWorkflowYAML parses a GitHub Actions workflow and checks its structure.
+// It returns line-numbered diagnostics; an empty slice means the workflow is valid.
+func ValidateWorkflowYAML(content string) []Diagnostic {
+	v := &workflowValidator{diagnostics: []Diagnostic{}}
+
+	file, err := parser.ParseBytes([]byte(content), 0)
+	if err != nil {
+		v.addSyntaxError(err)
+		return v.diagnostics
+	}
+
+	if len(file.Docs) == 0 || file.Docs[0].Body == nil {
+		v.add(SeverityError, nil, "", "workflow file is empty")
+		return v.diagnostics
+	}
+	if len(file.Docs) > 1 {
+		v.add(SeverityError, file.Docs[1].Body, "", "workflow file must contain a single YAML document")
+	}
+
+	v.validateWorkflow(file.Docs[0].Body)
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		return v.diagnostics[i].Line < v.diagnostics[j].Line
+	})
+	return v.diagnostics
+}
+
+// workflowValidator accumulates diagnostics while walking a workflow AST
+type workflowValidator struct {
+	diagnostics []Diagnostic
+}
+
+func (v *workflowValidator) add(severity Severity, node ast.Node, path, format string, args ...interface{}) {
+	d := Diagnostic{
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if tk := nodeToken(node); tk != nil && tk.Position != nil {
+		d.Line = tk.Position.Line
+		d.Column = tk.Position.Column
+	}
+	v.diagnostics = append(v.diagnostics, d)
+}
+
+func (v *workflowValidator) addSyntaxError(err error) {
+	d := Diagnostic{Severity: SeverityError, Message: err.Error()}
+
+	var tokenErr tokenError
+	if errors.As(err, &tokenErr) {
+		d.Message = tokenErr.GetMessage()
+		if tk := tokenErr.GetToken(); tk != nil && tk.Position != nil {
+			d.Line = tk.Position.Line
+			d.Column = tk.Position.Column
+		}
+	}
+	v.diagnostics = append(v.diagnostics, d)
+}
+
+// tokenError is implemented by go-yaml syntax errors
+type tokenError interface {
+	error
+	GetMessage() string
+	GetToken() *token.Token
+}
+
+func (v *workflowValidator) validateWorkflow(root ast.Node) {
+	entries, ok := mappingEntries(root)
+	if !ok {
+		v.add(SeverityError, root, "", "workflow must be a mapping")
+		return
+	}
+
+	var onNode, jobsNode ast.Node
+	for _, entry := range entries {
+		key := keyName(entry)
+		switch key {
+		case "on":
+			onNode = entry.Value
+		case "jobs":
+			jobsNode = entry.Value
+		}
+		if !workflowKeys[key] {
+			v.add(SeverityError, entry.Key, key, "unexpected top-level key '%s'", key)
+		}
+	}
+
+	if onNode == nil {
+		v.add(SeverityError, root, "on", "workflow must define 'on' triggers")
+	} else {
+		v.validateTriggers(onNode)
+	}
+
+	if jobsNode == nil {
+		v.add(SeverityError, root, "jobs", "workflow must define 'jobs'")
+		return
+	}
+	v.validateJobs(jobsNode)
+}
+
+func (v *workflowValidator) validateTriggers(node ast.Node) {
+	node = unwrapNode(node)
+	switch n := node.(type) {
+	case *ast.StringNode:
+		v.checkEvent(n, n.Value)
+	case *ast.SequenceNode:
+		for _, item := range n.Values {
+			if s, ok := unwrapNode(item).(*ast.StringNode); ok {
+				v.checkEvent(s, s.Value)
+			} else {
+				v.add(SeverityError, item, "on", "event list entries must be strings")
+			}
+		}
+	default:
+		entries, ok := mappingEntries(node)
+		if !ok {
+			v.add(SeverityError, node, "on", "'on' must be an event name, a list of events or a mapping")
+			return
+		}
+		for _, entry := range entries {
+			v.checkEvent(entry.Key, keyName(entry))
+		}
+	}
+}
+
+func (v *workflowValidator) checkEvent(node ast.Node, event string) {
+	if !workflowEvents[event] {
+		v.add(SeverityWarning, node, "on."+event, "unknown workflow trigger '%s'", event)
+	}
+}
+
+func (v *workflowValidator) validateJobs(node ast.Node) {
+	entries, ok := mappingEntries(node)
+	if !ok || len(entries) == 0 {
+		v.add(SeverityError, node, "jobs", "'jobs' must be a non-empty mapping of job IDs to jobs")
+		return
+	}
+
+	jobs := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		jobs[keyName(entry)] = true
+	}
+
+	needs := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		jobID := keyName(entry)
+		if !jobIDPattern.MatchString(jobID) {
+			v.add(SeverityError, entry.Key, "jobs."+jobID, "job ID '%s' must start with a letter or '_' and contain only alphanumerics, '-' or '_'", jobID)
+		}
+		needs[jobID] = v.validateJob(jobID, entry.Value, jobs)
+	}
+
+	v.checkNeedsCycles(entries, needs)
+}
+
+// validateJob checks a single job and returns the job IDs it needs
+func (v *workflowValidator) validateJob(jobID string, node ast.Node, jobs map[string]bool) []string {
+	path := "jobs." + jobID
+	entries, ok := mappingEntries(node)
+	if !ok {
+		v.add(SeverityError, node, path, "job '%s' must be a mapping", jobID)
+		return nil
+	}
+
+	fields := make(map[string]*ast.MappingValueNode, len(entries))
+	for _, entry := range entries {
+		key := keyName(entry)
+		fields[key] = entry
+		if !jobKeys[key] {
+			v.add(SeverityError, entry.Key, path+"."+key, "unexpected key '%s' in job '%s'", key, jobID)
+		}
+	}
+
+	if uses, ok := fields["uses"]; ok {
+		for key, entry := range fields {
+			if jobKeys[key] && !reusableJobKeys[key] {
+				v.add(SeverityError, entry.Key, path+"."+key, "'%s' is not supported on a job that calls a reusable workflow", key)
+			}
+		}
+		if value, ok := scalarString(uses.Value); !ok || !reusableWorkflowPattern.MatchString(value) {
+			v.add(SeverityError, uses.Value, path+".uses", "reusable workflow reference must be '{owner}/{repo}/.github/workflows/{file}.yml@{ref}' or './.github/workflows/{file}.yml'")
+		}
+		if with, ok := fields["with"]; ok {
+			v.checkScalarMapping(with.Value, path+".with")
+		}
+		if secrets, ok := fields["secrets"]; ok {
+			if value, ok := scalarString(secrets.Value); !ok || value != "inherit" {
+				v.checkScalarMapping(secrets.Value, path+".secrets")
+			}
+		}
+	} else {
+		if _, ok := fields["runs-on"]; !ok {
+			v.add(SeverityError, node, path, "job '%s' must define 'runs-on' or call a reusable workflow with 'uses'", jobID)
+		}
+		if steps, ok := fields["steps"]; ok {
+			v.validateSteps(steps.Value, path+".steps")
+		} else {
+			v.add(SeverityError, node, path, "job '%s' must define 'steps'", jobID)
+		}
+		for _, key := range []string{"with", "secrets"} {
+			if entry, ok := fields[key]; ok {
+				v.add(SeverityError, entry.Key, path+"."+key, "'%s' is only valid on a job that calls a reusable workflow", key)
+			}
+		}
+	}
+
+	var matrixKeys map[string]bool
+	if strategy, ok := fields["strategy"]; ok {
+		matrixKeys = v.validateStrategy(strategy.Value, path+".strategy")
+	}
+	if matrixKeys != nil {
+		v.checkMatrixReferences(node, path, matrixKeys)
+	}
+
+	var needed []string
+	if entry, ok := fields["needs"]; ok {
+		needed = v.validateNeeds(jobID, entry.Value, jobs)
+	}
+	return needed
+}
+
+func (v *workflowValidator) validateNeeds(jobID string, node ast.Node, jobs map[string]bool) []string {
+	path := "jobs." + jobID + ".needs"
+	var refs []ast.Node
+
+	node = unwrapNode(node)
+	if seq, ok := node.(*ast.SequenceNode); ok {
+		refs = seq.Values
+	} else {
+		refs = []ast.Node{node}
+	}
+
+	needed := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		name, ok := scalarString(ref)
+		if !ok {
+			v.add(SeverityError, ref, path, "'needs' must be a job ID or a list of job IDs")
+			continue
+		}
+		if name == jobID {
+			v.add(SeverityError, ref, path, "job '%s' cannot need itself", jobID)
+			continue
+		}
+		if !jobs[name] {
+			v.add(SeverityError, ref, path, "job '%s' needs unknown job '%s'", jobID, name)
+			continue
+		}
+		needed = append(needed, name)
+	}
+	return needed
+}
+
+func (v *workflowValidator) checkNeedsCycles(entries []*ast.MappingValueNode, needs map[string][]string) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(needs))
+
+	var visit func(jobID string) bool
+	visit = func(jobID string) bool {
+		switch state[jobID] {
+		case visiting:
+			return true
+		case done:
+			return false
+		}
+		state[jobID] = visiting
+		for _, dep := range needs[jobID] {
+			if visit(dep) {
+				return true
+			}
+		}
+		state[jobID] = done
+		return false
+	}
+
+	for _, entry := range entries {
+		jobID := keyName(entry)
+		if state[jobID] == unvisited && visit(jobID) {
+			v.add(SeverityError, entry.Key, "jobs."+jobID+".needs", "job '%s' is part of a 'needs' cycle", jobID)
+		}
+	}
+}
+
+func (v *workflowValidator) validateSteps(node ast.Node, path string) {
+	seq, ok := unwrapNode(node).(*ast.SequenceNode)
+	if !ok || len(seq.Values) == 0 {
+		v.add(SeverityError, node, path, "'steps' must be a non-empty list")
+		return
+	}
+
+	for i, item := range seq.Values {
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+		entries, ok := mappingEntries(item)
+		if !ok {
+			v.add(SeverityError, item, stepPath, "step must be a mapping")
+			continue
+		}
+
+		fields := make(map[string]*ast.MappingValueNode, len(entries))
+		for _, entry := range entries {
+			key := keyName(entry)
+			fields[key] = entry
+			if !stepKeys[key] {
+				v.add(SeverityError, entry.Key, stepPath+"."+key, "unexpected key '%s' in step", key)
+			}
+		}
+
+		uses, hasUses := fields["uses"]
+		_, hasRun := fields["run"]
+		switch {
+		case hasUses && hasRun:
+			v.add(SeverityError, item, stepPath, "step cannot define both 'uses' and 'run'")
+		case !hasUses && !hasRun:
+			v.add(SeverityError, item, stepPath, "step must define either 'uses' or 'run'")
+		case hasUses:
+			if value, ok := scalarString(uses.Value); !ok || !stepActionPattern.MatchString(value) {
+				v.add(SeverityError, uses.Value, stepPath+".uses", "action reference must be '{owner}/{repo}@{ref}', './{path}' or 'docker://{image}'")
+			}
+		}
+
+		if with, ok := fields["with"]; ok {
+			if !hasUses {
+				v.add(SeverityError, with.Key, stepPath+".with", "'with' requires 'uses'")
+			}
+			v.checkScalarMapping(with.Value, stepPath+".with")
+		}
+	}
+}
+
+// validateStrategy checks a job strategy and returns the keys available as
+// matrix.<key>, or nil when the matrix is built from an expression
+func (v *workflowValidator) validateStrategy(node ast.Node, path string) map[string]bool {
+	entries, ok := mappingEntries(node)
+	if !ok {
+		v.add(SeverityError, node, path, "'strategy' must be a mapping")
+		return nil
+	}
+
+	var matrixNode ast.Node
+	for _, entry := range entries {
+		key := keyName(entry)
+		if !strategyKeys[key] {
+			v.add(SeverityError, entry.Key, path+"."+key, "unexpected key '%s' in strategy", key)
+		}
+		if key == "matrix" {
+			matrixNode = entry.Value
+		}
+	}
+
+	if matrixNode == nil {
+		return nil
+	}
+	return v.validateMatrix(matrixNode, path+".matrix")
+}
+
+func (v *workflowValidator) validateMatrix(node ast.Node, path string) map[string]bool {
+	if value, ok := scalarString(node); ok && expressionPattern.MatchString(value) {
+		return nil
+	}
+
+	entries, ok := mappingEntries(node)
+	if !ok || len(entries) == 0 {
+		v.add(SeverityError, node, path, "'matrix' must be a non-empty mapping or an expression")
+		return nil
+	}
+
+	dimensions := make(map[string]bool)
+	available := make(map[string]bool)
+	var excludeNode ast.Node
+
+	for _, entry := range entries {
+		key := keyName(entry)
+		switch key {
+		case "include":
+			for _, combination := range v.matrixCombinations(entry.Value, path+".include") {
+				for _, comboEntry := range combination {
+					available[keyName(comboEntry)] = true
+				}
+			}
+		case "exclude":
+			excludeNode = entry.Value
+		default:
+			dimensions[key] = true
+			available[key] = true
+			if value, ok := scalarString(entry.Value); ok && expressionPattern.MatchString(value) {
+				continue
+			}
+			seq, ok := unwrapNode(entry.Value).(*ast.SequenceNode)
+			if !ok || len(seq.Values) == 0 {
+				v.add(SeverityError, entry.Value, path+"."+key, "matrix key '%s' must be a non-empty list", key)
+			}
+		}
+	}
+
+	if excludeNode != nil {
+		for _, combination := range v.matrixCombinations(excludeNode, path+".exclude") {
+			for _, comboEntry := range combination {
+				key := keyName(comboEntry)
+				if !dimensions[key] {
+					v.add(SeverityError, comboEntry.Key, path+".exclude", "exclude key '%s' is not a matrix dimension", key)
+				}
+			}
+		}
+	}
+
+	return available
+}
+
+// matrixCombinations checks an include/exclude list and returns its entries
+func (v *workflowValidator) matrixCombinations(node ast.Node, path string) [][]*ast.MappingValueNode {
+	if value, ok := scalarString(node); ok && expressionPattern.MatchString(value) {
+		return nil
+	}
+
+	seq, ok := unwrapNode(node).(*ast.SequenceNode)
+	if !ok {
+		v.add(SeverityError, node, path, "matrix include/exclude must be a list of mappings")
+		return nil
+	}
+
+	combinations := make([][]*ast.MappingValueNode, 0, len(seq.Values))
+	for _, item := range seq.Values {
+		entries, ok := mappingEntries(item)
+		if !ok {
+			v.add(SeverityError, item, path, "matrix include/exclude entries must be mappings")
+			continue
+		}
+		combinations = append(combinations, entries)
+	}
+	return combinations
+}
+
+// checkMatrixReferences warns about matrix.<key> expressions that the job's matrix does not define
+func (v *workflowValidator) checkMatrixReferences(node ast.Node, path string, keys map[string]bool) {
+	ast.Walk(visitorFunc(func(n ast.Node) {
+		value, ok := scalarString(n)
+		if !ok || !strings.Contains(value, "${{") {
+			return
+		}
+		for _, match := range matrixRefPattern.FindAllStringSubmatch(value, -1) {
+			if !keys[match[1]] {
+				v.add(SeverityWarning, n, path, "'matrix.%s' is not defined by the job's matrix", match[1])
+			}
+		}
+	}), node)
+}
+
+// checkScalarMapping checks that a with:/secrets: block maps names to scalar values
+func (v *workflowValidator) checkScalarMapping(node ast.Node, path string) {
+	entries, ok := mappingEntries(node)
+	if !ok {
+		v.add(SeverityError, node, path, "'%s' must be a mapping of names to values", lastSegment(path))
+		return
+	}
+
+	for _, entry := range entries {
+		if !isScalar(entry.Value) {
+			v.add(SeverityError, entry.Value, path+"."+keyName(entry), "value of '%s' must be a string, number or boolean", keyName(entry))
+		}
+	}
+}
+
+// visitorFunc adapts a function to ast.Visitor
+type visitorFunc func(ast.Node)
+
+func (f visitorFunc) Visit(node ast.Node) ast.Visitor {
+	f(node)
+	return f
+}
+
+// mappingEntries returns the key/value pairs of a mapping node.
+// go-yaml represents single-entry mappings as a bare MappingValueNode.
+func mappingEntries(node ast.Node) ([]*ast.MappingValueNode, bool) {
+	switch n := unwrapNode(node).(type) {
+	case *ast.MappingNode:
+		return n.Values, true
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}, true
+	default:
+		return nil, false
+	}
+}
+
+// unwrapNode strips anchors and tags from a node
+func unwrapNode(node ast.Node) ast.Node {
+	for {
+		switch n := node.(type) {
+		case *ast.AnchorNode:
+			node = n.Value
+		case *ast.TagNode:
+			node = n.Value
+		default:
+			return node
+		}
+	}
+}
+
+func keyName(entry *ast.MappingValueNode) string {
+	if tk := nodeToken(entry.Key); tk != nil {
+		return tk.Value
+	}
+	return ""
+}
+
+func scalarString(node ast.Node) (string, bool) {
+	node = unwrapNode(node)
+	if !isScalar(node) {
+		return "", false
+	}
+	if n, ok := node.(*ast.LiteralNode); ok {
+		return n.Value.Value, true
+	}
+	if tk := nodeToken(node); tk != nil {
+		return tk.Value, true
+	}
+	return "", true
+}
+
+func isScalar(node ast.Node) bool {
+	node = unwrapNode(node)
+	if node == nil {
+		return true
+	}
+	switch node.Type() {
+	case ast.StringType, ast.IntegerType, ast.FloatType, ast.BoolType, ast.NullType,
+		ast.LiteralType, ast.InfinityType, ast.NanType:
+		return true
+	default:
+		return false
+	}
+}
+
+func nodeToken(node ast.Node) *token.Token {
+	if node == nil {
+		return nil
+	}
+	return node.GetToken()
+}
+
+func lastSegment(path string) string {
+	if i := strings.LastIndex(path, "."); i != -1 {
+		return path[i+1:]
+	}
+	return path
+}
+
+func keySet(keys ...string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return set
+}
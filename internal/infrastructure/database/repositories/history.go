@@ -0,0 +1,135 @@
+package database
+
+import (
+	"github.com/google/uuid"
+	"github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	"gorm.io/gorm"
+)
+
+// defaultHistoryLimit caps history queries that do not set a limit
+const defaultHistoryLimit = 50
+
+// requestActions are the history actions whose payload is a workflow request
+var requestActions = []workflow.HistoryAction{workflow.HistoryActionCreate, workflow.HistoryActionRegenerate}
+
+// HistoryRepository handles workflow history data access
+type HistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewHistoryRepository creates a new workflow history repository
+func NewHistoryRepository(db *gorm.DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// Create records a workflow history entry
+func (r *HistoryRepository) Create(history *workflow.History) error {
+	return r.db.Create(history).Error
+}
+
+// List returns history entries matching the filter, newest first
+func (r *HistoryRepository) List(filter workflow.HistoryFilter) ([]workflow.History, error) {
+	query := r.db.Model(&workflow.History{})
+
+	if filter.Owner != "" {
+		query = query.Where("owner = ?", filter.Owner)
+	}
+	if filter.Repository != "" {
+		query = query.Where("repository = ?", filter.Repository)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+
+	var histories []workflow.History
+	if err := query.Order("created_at DESC").Limit(limit).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// ListLatestRequests returns, for every workflow of an owner, the newest
+// successful history entry that stored its generation request
+func (r *HistoryRepository) ListLatestRequests(owner string) ([]workflow.History, error) {
+	var histories []workflow.History
+	err := r.db.
+		Select("DISTINCT ON (repository, workflow_name) *").
+		Where("owner = ?", owner).
+		Where("status = ? AND action IN ?", workflow.HistoryStatusSuccess, requestActions).
+		Where("request_payload IS NOT NULL").
+		Order("repository, workflow_name, created_at DESC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// ListRepositories returns the newest history entry of every repository with
+// workflow history. Only the owner, repository, user and creation time are loaded.
+func (r *HistoryRepository) ListRepositories() ([]workflow.History, error) {
+	var histories []workflow.History
+	err := r.db.
+		Select("DISTINCT ON (owner, repository) owner, repository, user_id, created_at").
+		Order("owner, repository, created_at DESC").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// ListWorkflowNames returns the names of the workflows recorded in a
+// repository's history, whatever the outcome of the attempts
+func (r *HistoryRepository) ListWorkflowNames(owner, repo string) ([]string, error) {
+	var names []string
+	err := r.db.Model(&workflow.History{}).
+		Where("owner = ? AND repository = ?", owner, repo).
+		Distinct().
+		Pluck("workflow_name", &names).Error
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// FindLatestRequest returns the newest successful history entry that stored
+// the generation request of a workflow, or nil if there is none. A non-nil
+// userID limits the search to that user's entries.
+func (r *HistoryRepository) FindLatestRequest(owner, repo, workflowName string, userID *uuid.UUID) (*workflow.History, error) {
+	var history workflow.History
+	query := r.db
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	err := query.
+		Where("owner = ? AND repository = ? AND workflow_name = ?", owner, repo, workflowName).
+		Where("status = ? AND action IN ?", workflow.HistoryStatusSuccess, requestActions).
+		Where("request_payload IS NOT NULL").
+		Order("created_at DESC").
+		First(&history).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &history, nil
+}
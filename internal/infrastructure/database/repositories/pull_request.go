@@ -0,0 +1,97 @@
+package database
+
+import (
+	"github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	"gorm.io/gorm"
+)
+
+// defaultPullRequestLimit caps pull request queries that do not set a limit
+const defaultPullRequestLimit = 50
+
+// PullRequestRepository handles tracked pull request data access
+type PullRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewPullRequestRepository creates a new tracked pull request repository
+func NewPullRequestRepository(db *gorm.DB) *PullRequestRepository {
+	return &PullRequestRepository{db: db}
+}
+
+// Create starts tracking a pull request
+func (r *PullRequestRepository) Create(pr *workflow.TrackedPullRequest) error {
+	return r.db.Create(pr).Error
+}
+
+// Update saves the refreshed state of a tracked pull request
+func (r *PullRequestRepository) Update(pr *workflow.TrackedPullRequest) error {
+	return r.db.Save(pr).Error
+}
+
+// ListHeadBranches returns the head branches of a repository's tracked pull requests
+func (r *PullRequestRepository) ListHeadBranches(owner, repo string) ([]string, error) {
+	var branches []string
+	err := r.db.Model(&workflow.TrackedPullRequest{}).
+		Where("owner = ? AND repository = ?", owner, repo).
+		Distinct().
+		Pluck("head_branch", &branches).Error
+	if err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+// List returns tracked pull requests matching the filter, newest first
+func (r *PullRequestRepository) List(filter workflow.PullRequestFilter) ([]workflow.TrackedPullRequest, error) {
+	query := r.db.Model(&workflow.TrackedPullRequest{})
+
+	if filter.Owner != "" {
+		query = query.Where("owner = ?", filter.Owner)
+	}
+	if filter.Repository != "" {
+		query = query.Where("repository = ?", filter.Repository)
+	}
+	if filter.WorkflowName != "" {
+		query = query.Where("workflow_name = ?", filter.WorkflowName)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.State != "" {
+		query = query.Where("state = ?", filter.State)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultPullRequestLimit
+	}
+
+	var pulls []workflow.TrackedPullRequest
+	if err := query.Order("created_at DESC").Limit(limit).Find(&pulls).Error; err != nil {
+		return nil, err
+	}
+	return pulls, nil
+}
+
+// FindForEvent returns the tracked pull requests of a repository with one of
+// the given numbers or, when headSHA is set, with that head commit. Owner and
+// repository are matched case-insensitively, as webhooks use GitHub's spelling.
+func (r *PullRequestRepository) FindForEvent(owner, repo string, numbers []int, headSHA string) ([]workflow.TrackedPullRequest, error) {
+	query := r.db.
+		Where("LOWER(owner) = LOWER(?) AND LOWER(repository) = LOWER(?)", owner, repo)
+
+	switch {
+	case len(numbers) > 0:
+		query = query.Where("number IN ?", numbers)
+	case headSHA != "":
+		query = query.Where("head_sha = ?", headSHA)
+	default:
+		return nil, nil
+	}
+
+	var pulls []workflow.TrackedPullRequest
+	if err := query.Find(&pulls).Error; err != nil {
+		return nil, err
+	}
+	return pulls, nil
+}
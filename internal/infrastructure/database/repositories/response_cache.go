@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ResponseCacheRepository stores GitHub responses for conditional requests in
+// Postgres, so that they survive restarts and are shared between instances
+type ResponseCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewResponseCacheRepository creates a new GitHub response cache repository
+func NewResponseCacheRepository(db *gorm.DB) *ResponseCacheRepository {
+	return &ResponseCacheRepository{db: db}
+}
+
+// Get returns the response stored under key, or nil if there is none
+func (r *ResponseCacheRepository) Get(ctx context.Context, key string) (*github.CachedResponse, error) {
+	var entry github.CachedResponse
+	if err := r.db.WithContext(ctx).Where("key = ?", key).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Set stores a response, replacing the one stored under its key
+func (r *ResponseCacheRepository) Set(ctx context.Context, entry *github.CachedResponse) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(entry).Error
+}
+
+// DeleteOlderThan removes responses not stored again since before cutoff
+func (r *ResponseCacheRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result := r.db.Where("updated_at < ?", cutoff).Delete(&github.CachedResponse{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,183 @@
+package github
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// maxCachedBodySize is the largest response body kept for conditional requests
+const maxCachedBodySize = 1 << 20
+
+// CachedResponse is a GitHub response kept to revalidate with a conditional
+// request. GitHub does not count 304 Not Modified responses against the rate limit.
+type CachedResponse struct {
+	// Key identifies the token and URL of the request
+	Key          string `gorm:"primaryKey;size:64"`
+	ETag         string `gorm:"column:etag"`
+	LastModified string
+	StatusCode   int
+	// Link keeps the pagination links of the page
+	Link        string
+	ContentType string
+	Body        []byte
+	UpdatedAt   time.Time `gorm:"index"`
+}
+
+// TableName returns the table name for the CachedResponse model
+func (CachedResponse) TableName() string {
+	return "github_response_cache"
+}
+
+// ResponseCache stores GitHub responses for conditional requests
+type ResponseCache interface {
+	// Get returns the response stored under key, or nil if there is none
+	Get(ctx context.Context, key string) (*CachedResponse, error)
+	// Set stores a response under its key
+	Set(ctx context.Context, entry *CachedResponse) error
+}
+
+// responseCache is used by clients created afterwards; nil disables caching
+var responseCache ResponseCache
+
+// SetResponseCache sets the cache of GitHub clients created afterwards; nil
+// disables caching
+func SetResponseCache(cache ResponseCache) {
+	responseCache = cache
+}
+
+// cacheKey identifies a request by token and URL, so that responses are never
+// served to another token
+func cacheKey(token, url string) string {
+	sum := sha256.Sum256([]byte(token + "\n" + url))
+	return hex.EncodeToString(sum[:])
+}
+
+// response rebuilds the cached response, with the headers of the 304 response
+// that revalidated it
+func (e *CachedResponse) response(headers http.Header) *pkghttp.Response {
+	merged := headers.Clone()
+	merged.Del("Link")
+	if e.Link != "" {
+		merged.Set("Link", e.Link)
+	}
+	if e.ContentType != "" {
+		merged.Set("Content-Type", e.ContentType)
+	}
+	return &pkghttp.Response{
+		StatusCode: e.StatusCode,
+		Body:       e.Body,
+		Headers:    merged,
+	}
+}
+
+// cachedResponse looks up a response; cache failures only disable revalidation
+func (c *Client) cachedResponse(ctx context.Context, key string) *CachedResponse {
+	entry, err := c.cache.Get(ctx, key)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to read GitHub response cache")
+		return nil
+	}
+	if entry == nil || (entry.ETag == "" && entry.LastModified == "") {
+		return nil
+	}
+	return entry
+}
+
+// revalidate serves the cached response when GitHub answered 304 Not Modified,
+// and stores successful responses that can be revalidated
+func (c *Client) revalidate(ctx context.Context, key string, cached *CachedResponse, resp *pkghttp.Response) *pkghttp.Response {
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		return cached.response(resp.Headers)
+	}
+
+	if resp.StatusCode != http.StatusOK || len(resp.Body) > maxCachedBodySize {
+		return resp
+	}
+	entry := &CachedResponse{
+		Key:          key,
+		ETag:         resp.Headers.Get("ETag"),
+		LastModified: resp.Headers.Get("Last-Modified"),
+		StatusCode:   resp.StatusCode,
+		Link:         resp.Headers.Get("Link"),
+		ContentType:  resp.Headers.Get("Content-Type"),
+		Body:         resp.Body,
+		UpdatedAt:    time.Now(),
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return resp
+	}
+	if err := c.cache.Set(ctx, entry); err != nil {
+		logger.Warn().Err(err).Msg("Failed to write GitHub response cache")
+	}
+	return resp
+}
+
+// MemoryResponseCache is a ResponseCache that keeps the most recently used
+// responses in memory, up to a total body size
+type MemoryResponseCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// NewMemoryResponseCache creates an in-memory cache holding up to maxBytes of
+// response bodies
+func NewMemoryResponseCache(maxBytes int) *MemoryResponseCache {
+	return &MemoryResponseCache{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the response stored under key, or nil if there is none
+func (m *MemoryResponseCache) Get(ctx context.Context, key string) (*CachedResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	m.order.MoveToFront(element)
+	entry := *element.Value.(*CachedResponse)
+	return &entry, nil
+}
+
+// Set stores a response, evicting the least recently used ones beyond the size limit
+func (m *MemoryResponseCache) Set(ctx context.Context, entry *CachedResponse) error {
+	if len(entry.Body) > m.maxBytes {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *entry
+	if element, ok := m.entries[entry.Key]; ok {
+		m.size -= len(element.Value.(*CachedResponse).Body)
+		element.Value = &stored
+		m.order.MoveToFront(element)
+	} else {
+		m.entries[entry.Key] = m.order.PushFront(&stored)
+	}
+	m.size += len(stored.Body)
+
+	for m.size > m.maxBytes {
+		oldest := m.order.Back()
+		evicted := m.order.Remove(oldest).(*CachedResponse)
+		delete(m.entries, evicted.Key)
+		m.size -= len(evicted.Body)
+	}
+	return nil
+}
@@ -4,26 +4,81 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
 )
 
 // Client handles GitHub API interactions
 type Client struct {
 	httpClient *pkghttp.Client
 	baseURL    string
+	rateLimits *RateLimitTracker
+	cache      ResponseCache
+}
+
+// defaultBaseURL is the URL of the GitHub REST API
+const defaultBaseURL = "https://api.github.com"
+
+// baseURL is the API URL of clients created afterwards
+var baseURL = defaultBaseURL
+
+// SetBaseURL sets the API URL of clients created afterwards, such as a local
+// server in tests; "" restores the GitHub REST API
+func SetBaseURL(url string) {
+	if url == "" {
+		url = defaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(url, "/")
 }
 
 // NewClient creates a new GitHub API client
 func NewClient() *Client {
 	return &Client{
 		httpClient: pkghttp.NewClient(),
-		baseURL:    "https://api.github.com",
+		baseURL:    baseURL,
+		rateLimits: rateLimits,
+		cache:      responseCache,
 	}
 }
 
-// doRequest performs a GitHub API request with standard headers
+// doRequest performs a GitHub API request with standard headers. Requests are
+// held back while the token's rate limit is exhausted, and a request rejected
+// by a rate limit is sent once more after waiting, as the policy allows.
 func (c *Client) doRequest(ctx context.Context, token, method, path string, body interface{}) (*pkghttp.Response, error) {
+	resource := rateLimitResource(path)
+	if resource != "" {
+		if err := c.rateLimits.Wait(ctx, token, resource); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.send(ctx, token, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if rlErr := rateLimitError(resp); rlErr != nil && resource != "" {
+		logger.Warn().
+			Str("method", method).
+			Str("path", path).
+			Bool("secondary", rlErr.Secondary).
+			Time("reset", rlErr.Reset).
+			Msg("GitHub rate limit exceeded")
+
+		if c.rateLimits.waitFor(ctx, rlErr) == nil {
+			return c.send(ctx, token, method, path, body)
+		}
+	}
+
+	return resp, nil
+}
+
+// send performs a single GitHub API request and records the token's rate
+// limit. GET requests with a cached response are sent as conditional requests,
+// and the cached response is returned when it is still current.
+func (c *Client) send(ctx context.Context, token, method, path string, body interface{}) (*pkghttp.Response, error) {
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
 
 	headers := map[string]string{
@@ -32,6 +87,21 @@ func (c *Client) doRequest(ctx context.Context, token, method, path string, body
 		"X-GitHub-Api-Version": "2022-11-28",
 	}
 
+	// The rate limit endpoint is free, so it is never served from the cache
+	var key string
+	var cached *CachedResponse
+	if c.cache != nil && method == http.MethodGet && rateLimitResource(path) != "" {
+		key = cacheKey(token, url)
+		cached = c.cachedResponse(ctx, key)
+		switch {
+		case cached == nil:
+		case cached.ETag != "":
+			headers["If-None-Match"] = cached.ETag
+		default:
+			headers["If-Modified-Since"] = cached.LastModified
+		}
+	}
+
 	resp, err := c.httpClient.Do(ctx, pkghttp.Request{
 		Method:  method,
 		URL:     url,
@@ -43,15 +113,24 @@ func (c *Client) doRequest(ctx context.Context, token, method, path string, body
 		return nil, fmt.Errorf("github api request failed: %w", err)
 	}
 
+	c.rateLimits.Update(token, resp)
+	if key != "" {
+		resp = c.revalidate(ctx, key, cached, resp)
+	}
 	return resp, nil
 }
 
-// checkResponse checks if the response is successful
+// checkResponse checks if the response is successful. Rate limit rejections
+// are returned as a *RateLimitError rather than ErrForbidden.
 func checkResponse(resp *pkghttp.Response) error {
 	if resp.IsSuccess() {
 		return nil
 	}
 
+	if rlErr := rateLimitError(resp); rlErr != nil {
+		return rlErr
+	}
+
 	switch resp.StatusCode {
 	case http.StatusUnauthorized:
 		return ErrUnauthorized
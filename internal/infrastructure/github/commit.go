@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// CommitFile is a file written by a commit
+type CommitFile struct {
+	Path    string
+	Content string
+}
+
+// CommitBuilder collects files and writes them as a single commit through the
+// Git Data API, so changes to many files land together or not at all
+type CommitBuilder struct {
+	client *WorkflowClient
+	owner  string
+	repo   string
+	files  []CommitFile
+}
+
+// NewCommit starts a commit to a repository
+func (wc *WorkflowClient) NewCommit(owner, repo string) *CommitBuilder {
+	return &CommitBuilder{client: wc, owner: owner, repo: repo}
+}
+
+// AddFile adds a file to the commit, replacing an earlier file with the same path
+func (b *CommitBuilder) AddFile(path, content string) *CommitBuilder {
+	for i := range b.files {
+		if b.files[i].Path == path {
+			b.files[i].Content = content
+			return b
+		}
+	}
+	b.files = append(b.files, CommitFile{Path: path, Content: content})
+	return b
+}
+
+// Files returns the paths of the files in the commit
+func (b *CommitBuilder) Files() []string {
+	paths := make([]string, len(b.files))
+	for i, f := range b.files {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// Create writes the files as a commit on top of parentSHA and returns the SHA
+// of the new commit. No ref is moved; create a branch at the commit to
+// publish it.
+func (b *CommitBuilder) Create(ctx context.Context, token, parentSHA, message string) (string, error) {
+	if len(b.files) == 0 {
+		return "", errors.New("commit has no files")
+	}
+
+	parent, err := b.client.GetCommit(ctx, token, b.owner, b.repo, parentSHA)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parent commit: %w", err)
+	}
+
+	entries := make([]map[string]interface{}, 0, len(b.files))
+	for _, f := range b.files {
+		blobSHA, err := b.client.createBlob(ctx, token, b.owner, b.repo, f.Content)
+		if err != nil {
+			return "", fmt.Errorf("failed to create blob for %s: %w", f.Path, err)
+		}
+		entries = append(entries, map[string]interface{}{
+			"path": f.Path,
+			"mode": "100644",
+			"type": "blob",
+			"sha":  blobSHA,
+		})
+	}
+
+	treeSHA, err := b.client.createTree(ctx, token, b.owner, b.repo, parent.Tree.SHA, entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/git/commits", b.owner, b.repo)
+	body := map[string]interface{}{
+		"message": message,
+		"tree":    treeSHA,
+		"parents": []string{parentSHA},
+	}
+
+	resp, err := b.client.doRequest(ctx, token, http.MethodPost, path, body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to create commit: %s", resp.GetErrorMessage())
+	}
+
+	var commit GitCommit
+	if err := resp.UnmarshalJSON(&commit); err != nil {
+		return "", err
+	}
+
+	return commit.SHA, nil
+}
+
+// GetCommit retrieves a commit object
+func (wc *WorkflowClient) GetCommit(ctx context.Context, token, owner, repo, sha string) (*GitCommit, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/commits/%s", owner, repo, sha)
+	resp, err := wc.doRequest(ctx, token, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var commit GitCommit
+	if err := resp.UnmarshalJSON(&commit); err != nil {
+		return nil, err
+	}
+
+	return &commit, nil
+}
+
+// createBlob stores file content and returns the blob SHA
+func (wc *WorkflowClient) createBlob(ctx context.Context, token, owner, repo, content string) (string, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/blobs", owner, repo)
+	body := map[string]interface{}{
+		"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+		"encoding": "base64",
+	}
+
+	resp, err := wc.doRequest(ctx, token, http.MethodPost, path, body)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+
+	var blob Commit
+	if err := resp.UnmarshalJSON(&blob); err != nil {
+		return "", err
+	}
+
+	return blob.SHA, nil
+}
+
+// createTree creates a tree from a base tree and the given entries and returns the tree SHA
+func (wc *WorkflowClient) createTree(ctx context.Context, token, owner, repo, baseTree string, entries []map[string]interface{}) (string, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/trees", owner, repo)
+	body := map[string]interface{}{
+		"base_tree": baseTree,
+		"tree":      entries,
+	}
+
+	resp, err := wc.doRequest(ctx, token, http.MethodPost, path, body)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+
+	var tree Commit
+	if err := resp.UnmarshalJSON(&tree); err != nil {
+		return "", err
+	}
+
+	return tree.SHA, nil
+}
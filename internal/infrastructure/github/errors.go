@@ -8,6 +8,10 @@ var (
 	ErrUnauthorized = errors.New("unauthorized: invalid or expired token")
 	ErrForbidden    = errors.New("forbidden: insufficient permissions")
 	ErrNotFound     = errors.New("not found: resource does not exist or no access")
+	ErrRateLimited  = errors.New("rate limited: github api rate limit exceeded")
+
+	// Pagination errors
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
 
 	// Repository errors
 	ErrRepositoryNotFound = errors.New("repository not found")
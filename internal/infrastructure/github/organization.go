@@ -3,7 +3,6 @@ package github
 import (
 	"context"
 	"fmt"
-	"net/http"
 )
 
 // OrganizationClient handles GitHub organization operations
@@ -20,42 +19,19 @@ func NewOrganizationClient() *OrganizationClient {
 
 // GetUserOrganizations retrieves all organizations for the authenticated user
 func (oc *OrganizationClient) GetUserOrganizations(ctx context.Context, token string) ([]Organization, error) {
-	path := "/user/orgs"
-	resp, err := oc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var orgs []Organization
-	if err := resp.UnmarshalJSON(&orgs); err != nil {
-		return nil, err
-	}
-
-	return orgs, nil
+	return listAll(ctx, oc.Client, token, "/user/orgs", ListOptions{}, decodeArray[Organization])
 }
 
 // GetOrganizationRepositories retrieves all repositories for an organization
 func (oc *OrganizationClient) GetOrganizationRepositories(ctx context.Context, token, orgName string) ([]Repository, error) {
 	path := fmt.Sprintf("/orgs/%s/repos", orgName)
-	resp, err := oc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var repos []Repository
-	if err := resp.UnmarshalJSON(&repos); err != nil {
-		return nil, err
-	}
+	return listAll(ctx, oc.Client, token, path, ListOptions{}, decodeArray[Repository])
+}
 
-	return repos, nil
+// GetOrganizationRepositoriesPage retrieves a single page of the repositories of an organization
+func (oc *OrganizationClient) GetOrganizationRepositoriesPage(ctx context.Context, token, orgName string, req PageRequest) (*Page[Repository], error) {
+	path := fmt.Sprintf("/orgs/%s/repos", orgName)
+	return listPage(ctx, oc.Client, token, path, req, decodeArray[Repository])
 }
 
 // GetUserRepositories retrieves all repositories accessible to the user from their organizations
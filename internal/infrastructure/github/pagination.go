@@ -0,0 +1,274 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+)
+
+// maxPerPage is the largest page size GitHub list endpoints accept
+const maxPerPage = 100
+
+// defaultPerPage is GitHub's page size when none is requested
+const defaultPerPage = 30
+
+// ListOptions controls how many items a list method fetches
+type ListOptions struct {
+	// PerPage is the page size requested from GitHub; 100 when zero
+	PerPage int
+	// MaxItems stops the listing once this many items were fetched; unlimited when zero
+	MaxItems int
+}
+
+// PageRequest selects a single page of a list endpoint
+type PageRequest struct {
+	// Page is the 1-based page number; ignored when Cursor is set
+	Page int
+	// PerPage is the page size; 30 when zero. A cursor keeps the page size it was created with.
+	PerPage int
+	// Cursor is the NextCursor of a previous page
+	Cursor string
+}
+
+// Page is a single page of a list endpoint
+type Page[T any] struct {
+	Items []T
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string
+	// Total is the number of items of all pages, for endpoints that report it
+	Total *int
+}
+
+// PageIterator walks the pages of a GitHub list endpoint by following the
+// rel="next" URL of each response's Link header
+type PageIterator struct {
+	client *Client
+	ctx    context.Context
+	token  string
+	next   string
+	resp   *pkghttp.Response
+	err    error
+}
+
+// Pages returns an iterator over the pages of a list endpoint, starting at path
+func (c *Client) Pages(ctx context.Context, token, path string) *PageIterator {
+	return &PageIterator{client: c, ctx: ctx, token: token, next: path}
+}
+
+// Next fetches the next page. It returns false when there are no more pages
+// or a request failed, which Err reports.
+func (it *PageIterator) Next() bool {
+	if it.next == "" || it.err != nil {
+		return false
+	}
+
+	resp, err := it.client.doRequest(it.ctx, it.token, http.MethodGet, it.next, nil)
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.resp = resp
+	it.next, it.err = it.client.nextPagePath(resp.Headers.Get("Link"))
+	return true
+}
+
+// Response returns the page fetched by the last successful call to Next
+func (it *PageIterator) Response() *pkghttp.Response {
+	return it.resp
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// nextPagePath returns the API path of the rel="next" link of a Link header,
+// or "" on the last page. Links are only followed to the API host, since the
+// token is sent along.
+func (c *Client) nextPagePath(header string) (string, error) {
+	next := parseLinkHeader(header)["next"]
+	if next == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(next, c.baseURL+"/") {
+		return "", fmt.Errorf("%w: next page link points to another host: %s", ErrAPIFailed, next)
+	}
+	return strings.TrimPrefix(next, c.baseURL), nil
+}
+
+// parseLinkHeader maps the rel values of a Link header to their URLs
+func parseLinkHeader(header string) map[string]string {
+	links := make(map[string]string)
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "rel" {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				links[rel] = target[1 : len(target)-1]
+			}
+		}
+	}
+	return links
+}
+
+// withPerPage sets the per_page query parameter of path unless it has one
+func withPerPage(path string, perPage int) string {
+	if perPage <= 0 || perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	base, query, _ := strings.Cut(path, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil || values.Has("per_page") {
+		return path
+	}
+	values.Set("per_page", strconv.Itoa(perPage))
+	return base + "?" + values.Encode()
+}
+
+// listAll fetches the items of every page of a list endpoint, decoded by
+// decode, until the last page or opts.MaxItems
+func listAll[T any](ctx context.Context, c *Client, token, path string, opts ListOptions, decode func(*pkghttp.Response) ([]T, error)) ([]T, error) {
+	perPage := opts.PerPage
+	if opts.MaxItems > 0 && (perPage <= 0 || perPage > opts.MaxItems) {
+		perPage = opts.MaxItems
+	}
+
+	items := make([]T, 0)
+	pages := c.Pages(ctx, token, withPerPage(path, perPage))
+	for pages.Next() {
+		page, err := decode(pages.Response())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if opts.MaxItems > 0 && len(items) >= opts.MaxItems {
+			return items[:opts.MaxItems], nil
+		}
+	}
+	if err := pages.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// decodeArray decodes a page whose body is a JSON array
+func decodeArray[T any](resp *pkghttp.Response) ([]T, error) {
+	var items []T
+	if err := resp.UnmarshalJSON(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// decodeField returns a decoder for pages whose items are in an array field
+// of the body, such as "workflow_runs"
+func decodeField[T any](field string) func(*pkghttp.Response) ([]T, error) {
+	return func(resp *pkghttp.Response) ([]T, error) {
+		var body map[string]json.RawMessage
+		if err := resp.UnmarshalJSON(&body); err != nil {
+			return nil, err
+		}
+		var items []T
+		if raw, ok := body[field]; ok {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+			}
+		}
+		return items, nil
+	}
+}
+
+// listPage fetches the page of a list endpoint selected by req. Cursors only
+// carry query parameters, so a page is always fetched from path.
+func listPage[T any](ctx context.Context, c *Client, token, path string, req PageRequest, decode func(*pkghttp.Response) ([]T, error)) (*Page[T], error) {
+	pagePath, err := pagePath(path, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(ctx, token, http.MethodGet, pagePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	items, err := decode(resp)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page[T]{Items: items, Total: totalCount(resp)}
+
+	next, err := c.nextPagePath(resp.Headers.Get("Link"))
+	if err != nil {
+		return nil, err
+	}
+	if _, query, ok := strings.Cut(next, "?"); ok {
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(query))
+	}
+	return page, nil
+}
+
+// pagePath returns path with the query parameters of a page request
+func pagePath(path string, req PageRequest) (string, error) {
+	base, query, _ := strings.Cut(path, "?")
+
+	if req.Cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err != nil {
+			return "", ErrInvalidCursor
+		}
+		values, err := url.ParseQuery(string(decoded))
+		if err != nil {
+			return "", ErrInvalidCursor
+		}
+		return base + "?" + values.Encode(), nil
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", err
+	}
+	perPage := req.PerPage
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	values.Set("per_page", strconv.Itoa(min(perPage, maxPerPage)))
+	if req.Page > 1 {
+		values.Set("page", strconv.Itoa(req.Page))
+	}
+	return base + "?" + values.Encode(), nil
+}
+
+// totalCount returns the total_count of object responses, which some list
+// endpoints such as workflow runs report
+func totalCount(resp *pkghttp.Response) *int {
+	var body struct {
+		TotalCount *int `json:"total_count"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return nil
+	}
+	return body.TotalCount
+}
@@ -0,0 +1,400 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
+)
+
+// secondaryRateLimitWait is how long a token is held back after a secondary
+// rate limit that does not say when to retry, as GitHub recommends
+const secondaryRateLimitWait = time.Minute
+
+// RateLimitPolicy decides what happens to a request whose token has no budget left
+type RateLimitPolicy struct {
+	// Wait holds the request until the rate limit resets instead of failing it
+	Wait bool
+	// MaxWait is the longest a request is held; longer waits fail right away
+	MaxWait time.Duration
+}
+
+// DefaultRateLimitPolicy waits up to a minute for a rate limit to reset
+var DefaultRateLimitPolicy = RateLimitPolicy{Wait: true, MaxWait: time.Minute}
+
+// RateLimit is the budget of a token for one GitHub rate limit resource, such
+// as "core" or "search"
+type RateLimit struct {
+	Resource  string    `json:"resource"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	Reset     time.Time `json:"reset"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RateLimitStatus is the known budget of a token
+type RateLimitStatus struct {
+	Resources []RateLimit `json:"resources"`
+	// BlockedUntil is set while a secondary rate limit holds the token back
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+}
+
+// RateLimitError is returned when GitHub rejected a request for exceeding a
+// rate limit, or when the client did not send it because the token's budget
+// is exhausted. It wraps ErrRateLimited.
+type RateLimitError struct {
+	Resource string
+	// Secondary is set for secondary rate limits, which GitHub applies to
+	// bursts of requests regardless of the remaining budget
+	Secondary bool
+	// Reset is when requests are expected to succeed again
+	Reset   time.Time
+	Message string
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "primary"
+	if e.Secondary {
+		kind = "secondary"
+	}
+	msg := fmt.Sprintf("%s (%s limit of %s, resets at %s)", ErrRateLimited, kind, e.Resource, e.Reset.UTC().Format(time.RFC3339))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RetryAfter returns how long to wait before retrying
+func (e *RateLimitError) RetryAfter() time.Duration {
+	return max(time.Until(e.Reset), 0)
+}
+
+// tokenRateLimits is the budget of one token
+type tokenRateLimits struct {
+	resources    map[string]RateLimit
+	blockedUntil time.Time
+}
+
+// expired reports whether every limit of the token has reset by now, after
+// which its budget tells nothing about the next request
+func (l *tokenRateLimits) expired(now time.Time) bool {
+	if l.blockedUntil.After(now) {
+		return false
+	}
+	for _, limit := range l.resources {
+		if limit.Reset.After(now) {
+			return false
+		}
+	}
+	return true
+}
+
+// RateLimitTracker records the rate limit budget of each token from GitHub's
+// X-RateLimit-* and Retry-After headers, and holds back requests that would
+// exceed it. Tokens are kept by their hash until their limits reset.
+type RateLimitTracker struct {
+	mu     sync.Mutex
+	tokens map[string]*tokenRateLimits
+	policy RateLimitPolicy
+}
+
+// NewRateLimitTracker creates a rate limit tracker with the given policy
+func NewRateLimitTracker(policy RateLimitPolicy) *RateLimitTracker {
+	return &RateLimitTracker{
+		tokens: make(map[string]*tokenRateLimits),
+		policy: policy,
+	}
+}
+
+// rateLimits is shared by all clients, since a token's budget is shared by
+// all of its requests
+var rateLimits = NewRateLimitTracker(DefaultRateLimitPolicy)
+
+// SetRateLimitPolicy sets the policy of all GitHub clients
+func SetRateLimitPolicy(policy RateLimitPolicy) {
+	rateLimits.SetPolicy(policy)
+}
+
+// SetPolicy replaces the policy of the tracker
+func (t *RateLimitTracker) SetPolicy(policy RateLimitPolicy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.policy = policy
+}
+
+// Policy returns the policy of the tracker
+func (t *RateLimitTracker) Policy() RateLimitPolicy {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.policy
+}
+
+// Status returns the known budget of a token
+func (t *RateLimitTracker) Status(token string) RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := RateLimitStatus{Resources: make([]RateLimit, 0)}
+	limits, ok := t.tokens[tokenKey(token)]
+	if !ok {
+		return status
+	}
+	for _, limit := range limits.resources {
+		status.Resources = append(status.Resources, limit)
+	}
+	sort.Slice(status.Resources, func(i, j int) bool {
+		return status.Resources[i].Resource < status.Resources[j].Resource
+	})
+	if limits.blockedUntil.After(time.Now()) {
+		blockedUntil := limits.blockedUntil
+		status.BlockedUntil = &blockedUntil
+	}
+	return status
+}
+
+// Wait holds a request for resource until the token has budget for it, or
+// returns a RateLimitError right away when the policy does not allow waiting
+// that long
+func (t *RateLimitTracker) Wait(ctx context.Context, token, resource string) error {
+	rlErr := t.exhausted(token, resource)
+	if rlErr == nil {
+		return nil
+	}
+	return t.waitFor(ctx, rlErr)
+}
+
+// waitFor sleeps until a rate limit resets if the policy allows it
+func (t *RateLimitTracker) waitFor(ctx context.Context, rlErr *RateLimitError) error {
+	delay := rlErr.RetryAfter()
+	policy := t.Policy()
+	if !policy.Wait || delay > policy.MaxWait {
+		return rlErr
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// exhausted returns the rate limit that currently prevents requests of a
+// token to resource, if any
+func (t *RateLimitTracker) exhausted(token, resource string) *RateLimitError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limits, ok := t.tokens[tokenKey(token)]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	if limits.blockedUntil.After(now) {
+		return &RateLimitError{Resource: resource, Secondary: true, Reset: limits.blockedUntil}
+	}
+	if limit, ok := limits.resources[resource]; ok && limit.Remaining == 0 && limit.Reset.After(now) {
+		return &RateLimitError{Resource: resource, Reset: limit.Reset}
+	}
+	return nil
+}
+
+// Update records the budget reported by a response to a request of token
+func (t *RateLimitTracker) Update(token string, resp *pkghttp.Response) {
+	limit, ok := parseRateLimit(resp)
+	rlErr := rateLimitError(resp)
+	if !ok && rlErr == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limits := t.limits(token)
+	if ok {
+		limits.resources[limit.Resource] = limit
+	}
+	if rlErr != nil && rlErr.Secondary && rlErr.Reset.After(limits.blockedUntil) {
+		limits.blockedUntil = rlErr.Reset
+	}
+}
+
+// record stores budgets fetched from the rate limit endpoint
+func (t *RateLimitTracker) record(token string, resources []RateLimit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limits := t.limits(token)
+	for _, limit := range resources {
+		limits.resources[limit.Resource] = limit
+	}
+}
+
+// limits returns the budget of a token, creating it if needed and evicting
+// the tokens whose limits have all reset. t.mu must be held.
+func (t *RateLimitTracker) limits(token string) *tokenRateLimits {
+	key := tokenKey(token)
+	limits, ok := t.tokens[key]
+	if !ok {
+		now := time.Now()
+		for existing, other := range t.tokens {
+			if other.expired(now) {
+				delete(t.tokens, existing)
+			}
+		}
+		limits = &tokenRateLimits{resources: make(map[string]RateLimit)}
+		t.tokens[key] = limits
+	}
+	return limits
+}
+
+// tokenKey identifies a token without keeping it
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// rateLimitResource returns the rate limit resource a request path counts
+// against, or "" for the rate limit endpoint, which is free
+func rateLimitResource(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/rate_limit"):
+		return ""
+	case strings.HasPrefix(path, "/search/code"):
+		return "code_search"
+	case strings.HasPrefix(path, "/search/"):
+		return "search"
+	case strings.HasPrefix(path, "/graphql"):
+		return "graphql"
+	default:
+		return "core"
+	}
+}
+
+// parseRateLimit reads the X-RateLimit-* headers of a response
+func parseRateLimit(resp *pkghttp.Response) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(resp.Headers.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+
+	limit := RateLimit{
+		Resource:  resp.Headers.Get("X-RateLimit-Resource"),
+		Remaining: remaining,
+		UpdatedAt: time.Now(),
+	}
+	if limit.Resource == "" {
+		limit.Resource = "core"
+	}
+	limit.Limit, _ = strconv.Atoi(resp.Headers.Get("X-RateLimit-Limit"))
+	limit.Used, _ = strconv.Atoi(resp.Headers.Get("X-RateLimit-Used"))
+	if reset, err := strconv.ParseInt(resp.Headers.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		limit.Reset = time.Unix(reset, 0)
+	}
+	return limit, true
+}
+
+// rateLimitError tells rate limit rejections apart from other 403 and 429
+// responses, following GitHub's guidance: Retry-After is set for secondary
+// rate limits, an exhausted primary rate limit has no remaining budget, and
+// secondary rate limits without either should be retried after a minute.
+func rateLimitError(resp *pkghttp.Response) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = resp.UnmarshalJSON(&body)
+
+	rlErr := &RateLimitError{
+		Resource: resp.Headers.Get("X-RateLimit-Resource"),
+		Message:  body.Message,
+	}
+	if rlErr.Resource == "" {
+		rlErr.Resource = "core"
+	}
+
+	limit, hasLimit := parseRateLimit(resp)
+	retryAfter, hasRetryAfter := resp.RetryAfter()
+	switch {
+	case hasRetryAfter:
+		rlErr.Secondary = !hasLimit || limit.Remaining > 0
+		rlErr.Reset = time.Now().Add(retryAfter)
+	case hasLimit && limit.Remaining == 0:
+		rlErr.Reset = limit.Reset
+	case resp.StatusCode == http.StatusTooManyRequests || strings.Contains(strings.ToLower(body.Message), "rate limit"):
+		rlErr.Secondary = true
+		rlErr.Reset = time.Now().Add(secondaryRateLimitWait)
+	default:
+		return nil
+	}
+	return rlErr
+}
+
+// GetRateLimit fetches the budget of a token from GitHub and records it.
+// Requests to this endpoint do not count against the rate limit.
+func (c *Client) GetRateLimit(ctx context.Context, token string) (RateLimitStatus, error) {
+	resp, err := c.doRequest(ctx, token, http.MethodGet, "/rate_limit", nil)
+	if err != nil {
+		return RateLimitStatus{}, err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return RateLimitStatus{}, err
+	}
+
+	var body struct {
+		Resources map[string]struct {
+			Limit     int   `json:"limit"`
+			Remaining int   `json:"remaining"`
+			Used      int   `json:"used"`
+			Reset     int64 `json:"reset"`
+		} `json:"resources"`
+	}
+	if err := resp.UnmarshalJSON(&body); err != nil {
+		return RateLimitStatus{}, err
+	}
+
+	now := time.Now()
+	resources := make([]RateLimit, 0, len(body.Resources))
+	for name, resource := range body.Resources {
+		resources = append(resources, RateLimit{
+			Resource:  name,
+			Limit:     resource.Limit,
+			Remaining: resource.Remaining,
+			Used:      resource.Used,
+			Reset:     time.Unix(resource.Reset, 0),
+			UpdatedAt: now,
+		})
+	}
+	c.rateLimits.record(token, resources)
+
+	return c.rateLimits.Status(token), nil
+}
+
+// RateLimitStatus returns the budget of a token recorded from earlier responses
+func (c *Client) RateLimitStatus(token string) RateLimitStatus {
+	return c.rateLimits.Status(token)
+}
+
+// RateLimitPolicy returns the policy applied to requests of the client
+func (c *Client) RateLimitPolicy() RateLimitPolicy {
+	return c.rateLimits.Policy()
+}
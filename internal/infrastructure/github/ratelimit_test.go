@@ -0,0 +1,36 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitTrackerEvictsResetTokens(t *testing.T) {
+	tracker := NewRateLimitTracker(DefaultRateLimitPolicy)
+	now := time.Now()
+
+	tracker.record("reset", []RateLimit{{Resource: "core", Remaining: 10, Reset: now.Add(-time.Minute)}})
+	tracker.record("pending", []RateLimit{{Resource: "core", Remaining: 10, Reset: now.Add(time.Hour)}})
+	tracker.record("new", []RateLimit{{Resource: "core", Remaining: 10, Reset: now.Add(time.Hour)}})
+
+	if _, ok := tracker.tokens[tokenKey("reset")]; ok {
+		t.Error("token whose limits have reset was kept")
+	}
+	for _, token := range []string{"pending", "new"} {
+		if _, ok := tracker.tokens[tokenKey(token)]; !ok {
+			t.Errorf("token %q was evicted before its limits reset", token)
+		}
+	}
+}
+
+func TestRateLimitTrackerKeepsBlockedTokens(t *testing.T) {
+	tracker := NewRateLimitTracker(DefaultRateLimitPolicy)
+
+	tracker.record("blocked", nil)
+	tracker.tokens[tokenKey("blocked")].blockedUntil = time.Now().Add(time.Minute)
+	tracker.record("new", nil)
+
+	if _, ok := tracker.tokens[tokenKey("blocked")]; !ok {
+		t.Error("token held back by a secondary rate limit was evicted")
+	}
+}
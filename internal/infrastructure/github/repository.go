@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // RepositoryClient handles GitHub repository operations
@@ -21,61 +22,34 @@ func NewRepositoryClient() *RepositoryClient {
 // GetBranches retrieves all branches for a repository
 func (rc *RepositoryClient) GetBranches(ctx context.Context, token, owner, repo string) ([]Branch, error) {
 	path := fmt.Sprintf("/repos/%s/%s/branches", owner, repo)
-	resp, err := rc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var branches []Branch
-	if err := resp.UnmarshalJSON(&branches); err != nil {
-		return nil, err
-	}
+	return listAll(ctx, rc.Client, token, path, ListOptions{}, decodeArray[Branch])
+}
 
-	return branches, nil
+// GetBranchesPage retrieves a single page of the branches of a repository
+func (rc *RepositoryClient) GetBranchesPage(ctx context.Context, token, owner, repo string, req PageRequest) (*Page[Branch], error) {
+	path := fmt.Sprintf("/repos/%s/%s/branches", owner, repo)
+	return listPage(ctx, rc.Client, token, path, req, decodeArray[Branch])
 }
 
-// GetCommits retrieves commits for a branch
+// GetCommits retrieves the latest perPage commits of a branch
 func (rc *RepositoryClient) GetCommits(ctx context.Context, token, owner, repo, branch string, perPage int) ([]interface{}, error) {
-	path := fmt.Sprintf("/repos/%s/%s/commits?sha=%s&per_page=%d", owner, repo, branch, perPage)
-	resp, err := rc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var commits []interface{}
-	if err := resp.UnmarshalJSON(&commits); err != nil {
-		return nil, err
+	if perPage <= 0 {
+		perPage = defaultPerPage
 	}
-
-	return commits, nil
+	path := fmt.Sprintf("/repos/%s/%s/commits?sha=%s", owner, repo, url.QueryEscape(branch))
+	return listAll(ctx, rc.Client, token, path, ListOptions{MaxItems: perPage}, decodeArray[interface{}])
 }
 
 // GetTags retrieves all tags for a repository
 func (rc *RepositoryClient) GetTags(ctx context.Context, token, owner, repo string) ([]interface{}, error) {
 	path := fmt.Sprintf("/repos/%s/%s/tags", owner, repo)
-	resp, err := rc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var tags []interface{}
-	if err := resp.UnmarshalJSON(&tags); err != nil {
-		return nil, err
-	}
+	return listAll(ctx, rc.Client, token, path, ListOptions{}, decodeArray[interface{}])
+}
 
-	return tags, nil
+// GetTagsPage retrieves a single page of the tags of a repository
+func (rc *RepositoryClient) GetTagsPage(ctx context.Context, token, owner, repo string, req PageRequest) (*Page[interface{}], error) {
+	path := fmt.Sprintf("/repos/%s/%s/tags", owner, repo)
+	return listPage(ctx, rc.Client, token, path, req, decodeArray[interface{}])
 }
 
 // CreateTag creates a new tag
@@ -103,29 +77,10 @@ func (rc *RepositoryClient) CreateTag(ctx context.Context, token, owner, repo, t
 	return &ref, nil
 }
 
-// GetWorkflowRuns retrieves workflow runs for a repository
-func (rc *RepositoryClient) GetWorkflowRuns(ctx context.Context, token, owner, repo string, perPage int) ([]interface{}, error) {
-	path := fmt.Sprintf("/repos/%s/%s/actions/runs?per_page=%d", owner, repo, perPage)
-	resp, err := rc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var result map[string]interface{}
-	if err := resp.UnmarshalJSON(&result); err != nil {
-		return nil, err
-	}
-
-	runs, ok := result["workflow_runs"].([]interface{})
-	if !ok {
-		return []interface{}{}, nil
-	}
-
-	return runs, nil
+// GetWorkflowRuns retrieves a single page of the workflow runs of a repository, newest first
+func (rc *RepositoryClient) GetWorkflowRuns(ctx context.Context, token, owner, repo string, req PageRequest) (*Page[interface{}], error) {
+	path := fmt.Sprintf("/repos/%s/%s/actions/runs", owner, repo)
+	return listPage(ctx, rc.Client, token, path, req, decodeField[interface{}]("workflow_runs"))
 }
 
 // GetWorkflowRunDetail retrieves detailed information about a workflow run
@@ -148,25 +103,11 @@ func (rc *RepositoryClient) GetWorkflowRunDetail(ctx context.Context, token, own
 
 	// Get jobs
 	jobsPath := fmt.Sprintf("/repos/%s/%s/actions/runs/%d/jobs", owner, repo, runID)
-	jobsResp, err := rc.doRequest(ctx, token, http.MethodGet, jobsPath, nil)
+	jobs, err := listAll(ctx, rc.Client, token, jobsPath, ListOptions{}, decodeField[interface{}]("jobs"))
 	if err != nil {
 		return runDetail, nil, err
 	}
 
-	if err := checkResponse(jobsResp); err != nil {
-		return runDetail, nil, err
-	}
-
-	var jobsResult map[string]interface{}
-	if err := jobsResp.UnmarshalJSON(&jobsResult); err != nil {
-		return runDetail, nil, err
-	}
-
-	jobs, ok := jobsResult["jobs"].([]interface{})
-	if !ok {
-		return runDetail, []interface{}{}, nil
-	}
-
 	return runDetail, jobs, nil
 }
 
@@ -189,46 +130,18 @@ func (rc *RepositoryClient) GetJobLogs(ctx context.Context, token, owner, repo s
 func (rc *RepositoryClient) GetUserPackages(ctx context.Context, token, packageType string) ([]Package, error) {
 	path := "/user/packages"
 	if packageType != "" {
-		path = fmt.Sprintf("%s?package_type=%s", path, packageType)
+		path = fmt.Sprintf("%s?package_type=%s", path, url.QueryEscape(packageType))
 	}
 
-	resp, err := rc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var packages []Package
-	if err := resp.UnmarshalJSON(&packages); err != nil {
-		return nil, err
-	}
-
-	return packages, nil
+	return listAll(ctx, rc.Client, token, path, ListOptions{}, decodeArray[Package])
 }
 
 // GetOrgPackages retrieves packages for an organization
 func (rc *RepositoryClient) GetOrgPackages(ctx context.Context, token, org, packageType string) ([]Package, error) {
 	path := fmt.Sprintf("/orgs/%s/packages", org)
 	if packageType != "" {
-		path = fmt.Sprintf("%s?package_type=%s", path, packageType)
-	}
-
-	resp, err := rc.doRequest(ctx, token, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := checkResponse(resp); err != nil {
-		return nil, err
-	}
-
-	var packages []Package
-	if err := resp.UnmarshalJSON(&packages); err != nil {
-		return nil, err
+		path = fmt.Sprintf("%s?package_type=%s", path, url.QueryEscape(packageType))
 	}
 
-	return packages, nil
+	return listAll(ctx, rc.Client, token, path, ListOptions{}, decodeArray[Package])
 }
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// SecretsClient handles GitHub Actions secrets
+type SecretsClient struct {
+	*Client
+}
+
+// NewSecretsClient creates a new secrets client
+func NewSecretsClient() *SecretsClient {
+	return &SecretsClient{
+		Client: NewClient(),
+	}
+}
+
+// GetRepositoryPublicKey retrieves the key used to encrypt repository secrets
+func (sc *SecretsClient) GetRepositoryPublicKey(ctx context.Context, token, owner, repo string) (*PublicKey, error) {
+	path := fmt.Sprintf("/repos/%s/%s/actions/secrets/public-key", owner, repo)
+	resp, err := sc.doRequest(ctx, token, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var key PublicKey
+	if err := resp.UnmarshalJSON(&key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// PutRepositorySecret creates or updates a repository secret with a value
+// encrypted by EncryptSecret
+func (sc *SecretsClient) PutRepositorySecret(ctx context.Context, token, owner, repo, name, encryptedValue, keyID string) error {
+	path := fmt.Sprintf("/repos/%s/%s/actions/secrets/%s", owner, repo, name)
+	body := map[string]interface{}{
+		"encrypted_value": encryptedValue,
+		"key_id":          keyID,
+	}
+
+	resp, err := sc.doRequest(ctx, token, http.MethodPut, path, body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return checkResponse(resp)
+	}
+
+	return nil
+}
+
+// EncryptSecret seals value for the repository public key as GitHub expects:
+// a libsodium sealed box, base64 encoded
+func EncryptSecret(key *PublicKey, value string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(key.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode public key: %w", err)
+	}
+	if len(decoded) != 32 {
+		return "", fmt.Errorf("invalid public key length %d", len(decoded))
+	}
+
+	var recipient [32]byte
+	copy(recipient[:], decoded)
+
+	sealed, err := box.SealAnonymous(nil, []byte(value), &recipient, rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
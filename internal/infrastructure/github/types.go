@@ -1,5 +1,7 @@
 package github
 
+import "time"
+
 // Repository represents a GitHub repository
 type Repository struct {
 	ID            int64  `json:"id"`
@@ -9,6 +11,7 @@ type Repository struct {
 	Private       bool   `json:"private"`
 	HTMLURL       string `json:"html_url"`
 	DefaultBranch string `json:"default_branch"`
+	Archived      bool   `json:"archived"`
 	Owner         Owner  `json:"owner"`
 }
 
@@ -27,12 +30,35 @@ type Branch struct {
 	Protected bool   `json:"protected"`
 }
 
+// BranchRule represents a ruleset rule that applies to a branch
+type BranchRule struct {
+	Type      string `json:"type"`
+	RulesetID int64  `json:"ruleset_id"`
+}
+
 // Commit represents a commit
 type Commit struct {
 	SHA string `json:"sha"`
 	URL string `json:"url"`
 }
 
+// GitCommit represents a commit object of the Git Data API
+type GitCommit struct {
+	SHA       string   `json:"sha"`
+	Message   string   `json:"message"`
+	Author    GitActor `json:"author"`
+	Committer GitActor `json:"committer"`
+	Tree      Commit   `json:"tree"`
+	Parents   []Commit `json:"parents"`
+}
+
+// GitActor represents the author or committer of a commit
+type GitActor struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
 // Ref represents a Git reference
 type Ref struct {
 	Ref    string `json:"ref"`
@@ -47,13 +73,17 @@ type Ref struct {
 
 // PullRequest represents a pull request
 type PullRequest struct {
-	ID      int64  `json:"id"`
-	Number  int    `json:"number"`
-	State   string `json:"state"`
-	Title   string `json:"title"`
-	Body    string `json:"body"`
-	HTMLURL string `json:"html_url"`
-	Head    struct {
+	ID       int64      `json:"id"`
+	Number   int        `json:"number"`
+	State    string     `json:"state"`
+	Title    string     `json:"title"`
+	Body     string     `json:"body"`
+	HTMLURL  string     `json:"html_url"`
+	Draft    bool       `json:"draft"`
+	Merged   bool       `json:"merged"`
+	MergedAt *time.Time `json:"merged_at"`
+	ClosedAt *time.Time `json:"closed_at"`
+	Head     struct {
 		Ref string `json:"ref"`
 		SHA string `json:"sha"`
 	} `json:"head"`
@@ -63,6 +93,28 @@ type PullRequest struct {
 	} `json:"base"`
 }
 
+// CheckRun represents a check run reported for a commit
+type CheckRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// CombinedStatus represents the combined commit statuses of a ref
+type CombinedStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+// Review represents a pull request review
+type Review struct {
+	ID          int64      `json:"id"`
+	User        User       `json:"user"`
+	State       string     `json:"state"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+}
+
 // Content represents a file or directory in a repository
 type Content struct {
 	Name        string `json:"name"`
@@ -120,3 +172,9 @@ type PackageVersion struct {
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
+
+// PublicKey represents the key used to encrypt Actions secrets
+type PublicKey struct {
+	KeyID string `json:"key_id"`
+	Key   string `json:"key"`
+}
@@ -0,0 +1,100 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// WebhookEvent identifies the pull requests a webhook delivery is about
+type WebhookEvent struct {
+	Owner      string
+	Repository string
+	// PullRequests are pull request numbers named by the event
+	PullRequests []int
+	// HeadSHA is the commit of status events, which do not name pull requests
+	HeadSHA string
+}
+
+// webhookPayload holds the fields of the pull request, review, check and
+// status events needed to find their pull requests
+type webhookPayload struct {
+	Repository struct {
+		Name  string `json:"name"`
+		Owner struct {
+			Login string `json:"login"`
+		} `json:"owner"`
+	} `json:"repository"`
+	PullRequest *struct {
+		Number int `json:"number"`
+	} `json:"pull_request"`
+	CheckSuite *struct {
+		PullRequests []struct {
+			Number int `json:"number"`
+		} `json:"pull_requests"`
+	} `json:"check_suite"`
+	CheckRun *struct {
+		PullRequests []struct {
+			Number int `json:"number"`
+		} `json:"pull_requests"`
+	} `json:"check_run"`
+	SHA string `json:"sha"`
+}
+
+// VerifyWebhookSignature checks the X-Hub-Signature-256 header of a webhook
+// delivery against its body
+func VerifyWebhookSignature(secret, signature string, body []byte) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ParseWebhookEvent extracts the pull requests a webhook delivery is about. It
+// returns nil for events that do not affect pull request state, checks or reviews.
+func ParseWebhookEvent(eventType string, body []byte) (*WebhookEvent, error) {
+	switch eventType {
+	case "pull_request", "pull_request_review", "check_suite", "check_run", "status":
+	default:
+		return nil, nil
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	event := &WebhookEvent{
+		Owner:      payload.Repository.Owner.Login,
+		Repository: payload.Repository.Name,
+	}
+	switch {
+	case payload.PullRequest != nil:
+		event.PullRequests = []int{payload.PullRequest.Number}
+	case payload.CheckSuite != nil:
+		for _, pr := range payload.CheckSuite.PullRequests {
+			event.PullRequests = append(event.PullRequests, pr.Number)
+		}
+	case payload.CheckRun != nil:
+		for _, pr := range payload.CheckRun.PullRequests {
+			event.PullRequests = append(event.PullRequests, pr.Number)
+		}
+	case eventType == "status":
+		event.HeadSHA = payload.SHA
+	}
+
+	if len(event.PullRequests) == 0 && event.HeadSHA == "" {
+		return nil, nil
+	}
+	return event, nil
+}
@@ -3,8 +3,10 @@ package github
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -90,6 +92,94 @@ func (wc *WorkflowClient) CreateBranch(ctx context.Context, token, owner, repo,
 	return nil
 }
 
+// UpdateBranch moves a branch to a commit. Only fast-forward updates are
+// accepted, so commits pushed in the meantime are never lost.
+func (wc *WorkflowClient) UpdateBranch(ctx context.Context, token, owner, repo, branchName, sha string) error {
+	path := fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", owner, repo, branchName)
+	body := map[string]interface{}{
+		"sha":   sha,
+		"force": false,
+	}
+
+	resp, err := wc.doRequest(ctx, token, http.MethodPatch, path, body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to update branch: %s", resp.GetErrorMessage())
+	}
+
+	return nil
+}
+
+// IsBranchProtected reports whether pushes to a branch are restricted by
+// branch protection or by a repository ruleset. ErrNotFound is returned when
+// the branch does not exist.
+func (wc *WorkflowClient) IsBranchProtected(ctx context.Context, token, owner, repo, branchName string) (bool, error) {
+	path := fmt.Sprintf("/repos/%s/%s/branches/%s", owner, repo, branchName)
+	resp, err := wc.doRequest(ctx, token, http.MethodGet, path, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return false, err
+	}
+
+	var branch Branch
+	if err := resp.UnmarshalJSON(&branch); err != nil {
+		return false, err
+	}
+	if branch.Protected {
+		return true, nil
+	}
+
+	// Rulesets are not reflected in the protected flag. Servers without
+	// rulesets answer 404.
+	path = fmt.Sprintf("/repos/%s/%s/rules/branches/%s", owner, repo, branchName)
+	resp, err = wc.doRequest(ctx, token, http.MethodGet, path, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var rules []BranchRule
+	if err := resp.UnmarshalJSON(&rules); err != nil {
+		return false, err
+	}
+
+	return len(rules) > 0, nil
+}
+
+// DeleteBranch deletes a branch. ErrNotFound is returned when the branch does not exist.
+func (wc *WorkflowClient) DeleteBranch(ctx context.Context, token, owner, repo, branchName string) error {
+	path := fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", owner, repo, branchName)
+	resp, err := wc.doRequest(ctx, token, http.MethodDelete, path, nil)
+	if err != nil {
+		return err
+	}
+
+	// GitHub answers 422 for refs that do not exist
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		return ErrNotFound
+	}
+
+	return checkResponse(resp)
+}
+
+// ListBranchRefs retrieves the branches whose name starts with prefix
+func (wc *WorkflowClient) ListBranchRefs(ctx context.Context, token, owner, repo, prefix string) ([]Ref, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/matching-refs/heads/%s", owner, repo, prefix)
+	return listAll(ctx, wc.Client, token, path, ListOptions{}, decodeArray[Ref])
+}
+
 // CreateFile creates a file in the repository
 func (wc *WorkflowClient) CreateFile(ctx context.Context, token, owner, repo, filePath, content, message, branch string) error {
 	path := fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, filePath)
@@ -112,13 +202,14 @@ func (wc *WorkflowClient) CreateFile(ctx context.Context, token, owner, repo, fi
 }
 
 // CreatePullRequest creates a pull request
-func (wc *WorkflowClient) CreatePullRequest(ctx context.Context, token, owner, repo, headBranch, baseBranch, title, body string) (string, int, error) {
+func (wc *WorkflowClient) CreatePullRequest(ctx context.Context, token, owner, repo, headBranch, baseBranch, title, body string, draft bool) (string, int, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls", owner, repo)
 	requestBody := map[string]interface{}{
 		"title": title,
 		"body":  body,
 		"head":  headBranch,
 		"base":  baseBranch,
+		"draft": draft,
 	}
 
 	resp, err := wc.doRequest(ctx, token, http.MethodPost, path, requestBody)
@@ -138,25 +229,127 @@ func (wc *WorkflowClient) CreatePullRequest(ctx context.Context, token, owner, r
 	return prInfo.HTMLURL, prInfo.Number, nil
 }
 
-// GetWorkflowFiles retrieves all workflow files from a repository
-func (wc *WorkflowClient) GetWorkflowFiles(ctx context.Context, token, owner, repo string) ([]Content, error) {
-	path := fmt.Sprintf("/repos/%s/%s/contents/.github/workflows", owner, repo)
+// RequestReviewers requests reviews of a pull request from users and teams
+func (wc *WorkflowClient) RequestReviewers(ctx context.Context, token, owner, repo string, number int, reviewers, teamReviewers []string) error {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/requested_reviewers", owner, repo, number)
+	body := map[string]interface{}{
+		"reviewers":      nonNil(reviewers),
+		"team_reviewers": nonNil(teamReviewers),
+	}
+
+	resp, err := wc.doRequest(ctx, token, http.MethodPost, path, body)
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(resp)
+}
+
+// AddLabels adds labels to a pull request or issue, creating labels that do not exist yet
+func (wc *WorkflowClient) AddLabels(ctx context.Context, token, owner, repo string, number int, labels []string) error {
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d/labels", owner, repo, number)
+	body := map[string]interface{}{
+		"labels": labels,
+	}
+
+	resp, err := wc.doRequest(ctx, token, http.MethodPost, path, body)
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(resp)
+}
+
+// AddAssignees assigns users to a pull request or issue
+func (wc *WorkflowClient) AddAssignees(ctx context.Context, token, owner, repo string, number int, assignees []string) error {
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d/assignees", owner, repo, number)
+	body := map[string]interface{}{
+		"assignees": assignees,
+	}
+
+	resp, err := wc.doRequest(ctx, token, http.MethodPost, path, body)
+	if err != nil {
+		return err
+	}
+
+	return checkResponse(resp)
+}
+
+// nonNil returns an empty slice for nil, so it is sent as [] rather than null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// ListOpenPullRequests retrieves the open pull requests of a repository
+func (wc *WorkflowClient) ListOpenPullRequests(ctx context.Context, token, owner, repo string) ([]PullRequest, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls?state=open", owner, repo)
+	return listAll(ctx, wc.Client, token, path, ListOptions{}, decodeArray[PullRequest])
+}
+
+// GetPullRequest retrieves a pull request by number
+func (wc *WorkflowClient) GetPullRequest(ctx context.Context, token, owner, repo string, number int) (*PullRequest, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, number)
 	resp, err := wc.doRequest(ctx, token, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode == http.StatusNotFound {
-		// .github/workflows directory doesn't exist
-		return []Content{}, nil
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var pull PullRequest
+	if err := resp.UnmarshalJSON(&pull); err != nil {
+		return nil, err
+	}
+
+	return &pull, nil
+}
+
+// ListPullRequestReviews retrieves the reviews of a pull request, oldest first
+func (wc *WorkflowClient) ListPullRequestReviews(ctx context.Context, token, owner, repo string, number int) ([]Review, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", owner, repo, number)
+	return listAll(ctx, wc.Client, token, path, ListOptions{}, decodeArray[Review])
+}
+
+// ListCheckRuns retrieves the check runs of a commit
+func (wc *WorkflowClient) ListCheckRuns(ctx context.Context, token, owner, repo, ref string) ([]CheckRun, error) {
+	path := fmt.Sprintf("/repos/%s/%s/commits/%s/check-runs", owner, repo, ref)
+	return listAll(ctx, wc.Client, token, path, ListOptions{}, decodeField[CheckRun]("check_runs"))
+}
+
+// GetCombinedStatus retrieves the combined commit statuses of a ref
+func (wc *WorkflowClient) GetCombinedStatus(ctx context.Context, token, owner, repo, ref string) (*CombinedStatus, error) {
+	path := fmt.Sprintf("/repos/%s/%s/commits/%s/status", owner, repo, ref)
+	resp, err := wc.doRequest(ctx, token, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := checkResponse(resp); err != nil {
 		return nil, err
 	}
 
-	var files []Content
-	if err := resp.UnmarshalJSON(&files); err != nil {
+	var status CombinedStatus
+	if err := resp.UnmarshalJSON(&status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// GetWorkflowFiles retrieves all workflow files from a repository
+func (wc *WorkflowClient) GetWorkflowFiles(ctx context.Context, token, owner, repo string) ([]Content, error) {
+	path := fmt.Sprintf("/repos/%s/%s/contents/.github/workflows", owner, repo)
+	files, err := listAll(ctx, wc.Client, token, path, ListOptions{}, decodeArray[Content])
+	if errors.Is(err, ErrNotFound) {
+		// .github/workflows directory doesn't exist
+		return []Content{}, nil
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -197,8 +390,35 @@ func (wc *WorkflowClient) GetFileContent(ctx context.Context, token, owner, repo
 	return string(decodedContent), fileInfo.SHA, nil
 }
 
-// UpdateFile updates an existing file in the repository
-func (wc *WorkflowClient) UpdateFile(ctx context.Context, token, owner, repo, filePath, content, message, branch, sha string) error {
+// GetPathType returns whether a path on ref is a "file" or a "dir".
+// ErrNotFound is returned when the path does not exist.
+func (wc *WorkflowClient) GetPathType(ctx context.Context, token, owner, repo, filePath, ref string) (string, error) {
+	path := fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s", owner, repo, filePath, url.QueryEscape(ref))
+	resp, err := wc.doRequest(ctx, token, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+
+	// Directories are returned as a list of their entries
+	trimmed := strings.TrimSpace(string(resp.Body))
+	if strings.HasPrefix(trimmed, "[") {
+		return "dir", nil
+	}
+
+	var fileInfo Content
+	if err := resp.UnmarshalJSON(&fileInfo); err != nil {
+		return "", err
+	}
+
+	return fileInfo.Type, nil
+}
+
+// UpdateFile updates an existing file in the repository and returns the SHA of the commit
+func (wc *WorkflowClient) UpdateFile(ctx context.Context, token, owner, repo, filePath, content, message, branch, sha string) (string, error) {
 	path := fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, filePath)
 	body := map[string]interface{}{
 		"message": message,
@@ -209,12 +429,19 @@ func (wc *WorkflowClient) UpdateFile(ctx context.Context, token, owner, repo, fi
 
 	resp, err := wc.doRequest(ctx, token, http.MethodPut, path, body)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return checkResponse(resp)
+		return "", checkResponse(resp)
 	}
 
-	return nil
+	var result struct {
+		Commit Commit `json:"commit"`
+	}
+	if err := resp.UnmarshalJSON(&result); err != nil {
+		return "", err
+	}
+
+	return result.Commit.SHA, nil
 }
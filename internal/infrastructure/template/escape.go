@@ -0,0 +1,109 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expressionOpen starts a GitHub Actions expression
+const expressionOpen = "${{"
+
+// escapeExpr neutralizes GitHub Actions expressions in user input so that
+// "${{ ... }}" is rendered literally instead of being evaluated by the runner
+func escapeExpr(s string) string {
+	return strings.ReplaceAll(s, expressionOpen, "${{ '${{' }}")
+}
+
+// yamlEscape returns s escaped for use inside a double-quoted YAML scalar,
+// with GitHub Actions expressions neutralized
+func yamlEscape(s string) string {
+	s = escapeExpr(s)
+
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			switch {
+			case r < 0x20 || r == 0x7f:
+				fmt.Fprintf(&b, `\x%02X`, r)
+			case r == 0x85 || r == 0x2028 || r == 0x2029 || r == 0xFEFF:
+				fmt.Fprintf(&b, `\u%04X`, r)
+			default:
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// yamlQuote returns s as a double-quoted YAML scalar. Any value is safe to
+// interpolate this way: colons, '#', leading '*', '&', '!' or quotes cannot
+// change the document structure, and expressions are neutralized.
+func yamlQuote(s string) string {
+	return `"` + yamlEscape(s) + `"`
+}
+
+// yamlBlock returns s as a literal block scalar whose content lines are
+// indented by spaces, for values such as dotenv files. It is meant to follow
+// a "key: " on the same line. Values that cannot be represented as a literal
+// block (empty, starting with whitespace or ending in several newlines) are
+// double-quoted instead.
+func yamlBlock(spaces int, s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+
+	if strings.TrimSpace(s) == "" || strings.IndexFunc(s, isNonBlockRune) != -1 {
+		return yamlQuote(s)
+	}
+	if first := firstNonEmptyLine(s); first != "" && (first[0] == ' ' || first[0] == '\t') {
+		return yamlQuote(s)
+	}
+
+	// Keep chomping would also absorb blank lines the template emits after the
+	// block, so values ending in several newlines are quoted instead
+	trimmed := strings.TrimRight(s, "\n")
+	header := "|-"
+	switch trailing := len(s) - len(trimmed); {
+	case trailing == 1:
+		header = "|"
+	case trailing > 1:
+		return yamlQuote(s)
+	}
+
+	indent := strings.Repeat(" ", spaces)
+	lines := strings.Split(escapeExpr(trimmed), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+
+	return header + "\n" + strings.Join(lines, "\n")
+}
+
+// isNonBlockRune reports whether r cannot appear verbatim in a block scalar
+func isNonBlockRune(r rune) bool {
+	if r == '\n' || r == '\t' {
+		return false
+	}
+	return r < 0x20 || r == 0x7f || r == 0x85 || r == 0x2028 || r == 0x2029 || r == 0xFEFF
+}
+
+func firstNonEmptyLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
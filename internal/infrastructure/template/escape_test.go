@@ -0,0 +1,200 @@
+package template_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/domain/workflow"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/template"
+)
+
+// injectedExpression must never reach a generated workflow verbatim
+const injectedExpression = "${{ github.token }}"
+
+// hostileValues could change the structure of the YAML document or be
+// evaluated by the runner if they were interpolated unescaped
+var hostileValues = []struct {
+	name  string
+	value string
+}{
+	{"colon", "key: value"},
+	{"comment", "value # comment"},
+	{"alias", "*alias"},
+	{"anchor", "&anchor value"},
+	{"tag", "!!binary value"},
+	{"newlines", "first\nsecond: value\n- item"},
+	{"expression", injectedExpression},
+	{"expression in text", "echo ${{ github.token }} # leaked"},
+	{"quotes", `"double" and 'single'`},
+}
+
+func TestEscapeExpr(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"$ {{ x }}", "$ {{ x }}"},
+		{injectedExpression, "${{ '${{' }} github.token }}"},
+		{"a ${{ b }} c ${{ d }}", "a ${{ '${{' }} b }} c ${{ '${{' }} d }}"},
+	}
+	for _, tt := range tests {
+		if got := template.EscapeExpr(tt.in); got != tt.want {
+			t.Errorf("EscapeExpr(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestYAMLQuote(t *testing.T) {
+	for _, tt := range hostileValues {
+		t.Run(tt.name, func(t *testing.T) {
+			document := "key: " + template.YAMLQuote(tt.value) + "\nnext: ok\n"
+			assertDecodes(t, document, tt.value)
+		})
+	}
+}
+
+func TestYAMLBlock(t *testing.T) {
+	values := append(hostileValues, []struct {
+		name  string
+		value string
+	}{
+		{"trailing newline", "A=1\nB=2\n"},
+		{"leading space", "  indented"},
+		{"empty", ""},
+	}...)
+	for _, tt := range values {
+		t.Run(tt.name, func(t *testing.T) {
+			document := "outer:\n  key: " + template.YAMLBlock(4, tt.value) + "\n  next: ok\n"
+			var decoded struct {
+				Outer struct {
+					Key  string `yaml:"key"`
+					Next string `yaml:"next"`
+				} `yaml:"outer"`
+			}
+			if err := yaml.Unmarshal([]byte(document), &decoded); err != nil {
+				t.Fatalf("document does not parse: %v\n%s", err, document)
+			}
+			if want := template.EscapeExpr(tt.value); decoded.Outer.Key != want {
+				t.Errorf("decoded %q, want %q\n%s", decoded.Outer.Key, want, document)
+			}
+			if decoded.Outer.Next != "ok" {
+				t.Errorf("value changed the following key\n%s", document)
+			}
+			if strings.Contains(decoded.Outer.Key, injectedExpression) {
+				t.Errorf("expression survived escaping: %q", decoded.Outer.Key)
+			}
+		})
+	}
+}
+
+// assertDecodes checks that document decodes to key holding value with its
+// expressions neutralized, without disturbing the key after it
+func assertDecodes(t *testing.T, document, value string) {
+	t.Helper()
+	var decoded map[string]string
+	if err := yaml.Unmarshal([]byte(document), &decoded); err != nil {
+		t.Fatalf("document does not parse: %v\n%s", err, document)
+	}
+	if want := template.EscapeExpr(value); decoded["key"] != want {
+		t.Errorf("decoded %q, want %q\n%s", decoded["key"], want, document)
+	}
+	if decoded["next"] != "ok" {
+		t.Errorf("value changed the following key\n%s", document)
+	}
+	if strings.Contains(decoded["key"], injectedExpression) {
+		t.Errorf("expression survived escaping: %q", decoded["key"])
+	}
+}
+
+func TestGeneratorsEscapeUserInput(t *testing.T) {
+	store := template.NewStore(template.NewEmbeddedSource())
+	generators := map[string]func(interface{}) (string, error){
+		"ec2":        template.NewEC2Generator(store).Generate,
+		"kubernetes": template.NewKubernetesGenerator(store).Generate,
+	}
+
+	for _, tt := range hostileValues {
+		for name, generate := range generators {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				out, err := generate(hostileRequest(tt.value))
+				if err != nil {
+					t.Fatalf("Generate: %v", err)
+				}
+				if diagnostics := workflow.ValidateWorkflowYAML(out); workflow.HasErrors(diagnostics) {
+					t.Fatalf("generated workflow is invalid: %+v\n%s", diagnostics, out)
+				}
+				if strings.Contains(out, injectedExpression) {
+					t.Errorf("generated workflow contains %q\n%s", injectedExpression, out)
+				}
+			})
+		}
+	}
+}
+
+// hostileRequest returns a request of every deployment type with value in
+// each free-text field. Fields that are validated against a pattern before
+// generation, such as release tags and environment names, keep valid values.
+func hostileRequest(value string) *workflow.Request {
+	return &workflow.Request{
+		Owner:          value,
+		Repository:     value,
+		WorkflowName:   "deploy",
+		DeploymentType: workflow.DeploymentTypeEC2,
+		Projects: []workflow.Project{{
+			ID:                "api",
+			Name:              value,
+			DockerContextPath: value,
+			DockerfilePath:    value,
+			DotEnv: map[string]string{
+				"testing":    "GREETING=" + value + "\n# note: " + value + "\n",
+				"production": value,
+			},
+		}},
+		EC2CommonFields: &workflow.EC2CommonFields{
+			CredentialID:             value,
+			AWSRegion:                value,
+			JenkinsJobs:              value,
+			ReleaseTag:               "v1.0.0",
+			CodeownersEmails:         value,
+			DevopsStakeholdersEmails: value,
+		},
+		EC2Projects: []workflow.EC2Project{{
+			ID:               "api",
+			Name:             value,
+			Command:          value,
+			Port:             value,
+			DockerNetwork:    value,
+			MountPath:        value,
+			LogDriver:        value,
+			LogDriverOptions: value,
+		}},
+		KubernetesCommonFields: &workflow.KubernetesCommonFields{
+			JenkinsJobName:             value,
+			ReleaseTag:                 "v1.0.0",
+			HelmValuesRepository:       value,
+			CodeownersEmailIds:         value,
+			DevopsStakeholdersEmailIds: value,
+		},
+		KubernetesProjects: []workflow.KubernetesProject{{
+			ID:   "api",
+			Name: value,
+		}},
+		Environments: []workflow.Environment{
+			{Name: "testing", TagPattern: "v*-rc*", GitHubEnvironment: value},
+			{Name: "production", TagPattern: "v*", GitHubEnvironment: value},
+		},
+		Triggers: &workflow.Triggers{
+			WorkflowDispatch: &workflow.WorkflowDispatchTrigger{
+				Inputs: []workflow.DispatchInput{{
+					Name:        "reason",
+					Description: value,
+					Type:        workflow.DispatchInputString,
+					Default:     value,
+				}},
+			},
+		},
+	}
+}
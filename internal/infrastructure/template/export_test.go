@@ -0,0 +1,9 @@
+package template
+
+// Exported for the tests in package template_test, which cannot be in this
+// package because they use the workflow domain that imports it
+var (
+	EscapeExpr = escapeExpr
+	YAMLQuote  = yamlQuote
+	YAMLBlock  = yamlBlock
+)
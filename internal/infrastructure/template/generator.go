@@ -3,8 +3,11 @@ package template
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 	"text/template"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/filterpattern"
 )
 
 // Generator defines the interface for workflow template generation
@@ -15,11 +18,20 @@ type Generator interface {
 // BaseGenerator provides common template functionality
 type BaseGenerator struct {
 	funcMap template.FuncMap
+	store   *Store
 }
 
 // NewBaseGenerator creates a new base generator with common functions
-func NewBaseGenerator() *BaseGenerator {
-	funcMap := template.FuncMap{
+func NewBaseGenerator(store *Store) *BaseGenerator {
+	return &BaseGenerator{
+		funcMap: defaultFuncMap(),
+		store:   store,
+	}
+}
+
+// defaultFuncMap returns the functions available to workflow templates
+func defaultFuncMap() template.FuncMap {
+	return template.FuncMap{
 		"indent": func(spaces int, text string) string {
 			indent := strings.Repeat(" ", spaces)
 			lines := strings.Split(text, "\n")
@@ -30,11 +42,45 @@ func NewBaseGenerator() *BaseGenerator {
 			}
 			return strings.Join(lines, "\n")
 		},
+		"anyField":   anyField,
+		"tagRegexp":  tagRegexp,
+		"yamlQuote":  yamlQuote,
+		"yamlEscape": yamlEscape,
+		"yamlBlock":  yamlBlock,
+		"escapeExpr": escapeExpr,
 	}
+}
 
-	return &BaseGenerator{
-		funcMap: funcMap,
+// anyField reports whether any element of list has a non-zero value in the named field
+func anyField(list interface{}, field string) (bool, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false, fmt.Errorf("anyField: expected a list, got %s", v.Kind())
 	}
+
+	for i := 0; i < v.Len(); i++ {
+		item := reflect.Indirect(v.Index(i))
+		if item.Kind() != reflect.Struct {
+			return false, fmt.Errorf("anyField: expected a list of structs, got %s", item.Kind())
+		}
+		value := item.FieldByName(field)
+		if !value.IsValid() {
+			return false, fmt.Errorf("anyField: unknown field %s", field)
+		}
+		if !value.IsZero() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tagRegexp returns the regular expression equivalent to a GitHub tag filter pattern
+func tagRegexp(pattern string) (string, error) {
+	p, err := filterpattern.Parse(pattern)
+	if err != nil {
+		return "", err
+	}
+	return p.Regexp(), nil
 }
 
 // Execute executes a template with the given data
@@ -51,3 +97,29 @@ func (bg *BaseGenerator) Execute(name, tmpl string, data interface{}) (string, e
 
 	return buf.String(), nil
 }
+
+// ExecuteNamed executes the template loaded under name in the store, with
+// the store's partials available to {{template}} actions
+func (bg *BaseGenerator) ExecuteNamed(name string, data interface{}) (string, error) {
+	tmpl, err := bg.store.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := template.New(name + "-workflow").Funcs(bg.funcMap).Parse(tmpl.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	for _, partial := range bg.store.Partials() {
+		if _, err := t.New(partial.Name).Parse(partial.Body); err != nil {
+			return "", fmt.Errorf("failed to parse partial '%s': %w", partial.Name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,249 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+// templateExtensions lists the file suffixes recognized as workflow templates
+var templateExtensions = []string{".yml.tmpl", ".yaml.tmpl", ".tmpl"}
+
+// Source provides workflow template bodies keyed by template name
+type Source interface {
+	// Name describes where templates are loaded from
+	Name() string
+	// Fetch returns the template bodies and a version identifying this snapshot
+	Fetch(ctx context.Context) (map[string]string, string, error)
+}
+
+// EmbeddedSource serves the templates compiled into the binary
+type EmbeddedSource struct{}
+
+// NewEmbeddedSource creates a source for the built-in templates
+func NewEmbeddedSource() *EmbeddedSource {
+	return &EmbeddedSource{}
+}
+
+// Name returns the source description
+func (es *EmbeddedSource) Name() string {
+	return "embedded"
+}
+
+// Fetch returns the built-in templates
+func (es *EmbeddedSource) Fetch(ctx context.Context) (map[string]string, string, error) {
+	sub, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, "", err
+	}
+
+	files, err := readTemplates(sub)
+	if err != nil {
+		return nil, "", err
+	}
+	return files, checksum(files), nil
+}
+
+// DirSource loads templates from a directory on disk
+type DirSource struct {
+	path string
+}
+
+// NewDirSource creates a source reading templates from path
+func NewDirSource(path string) *DirSource {
+	return &DirSource{path: path}
+}
+
+// Name returns the source description
+func (ds *DirSource) Name() string {
+	return "dir:" + ds.path
+}
+
+// Fetch reads all templates from the directory
+func (ds *DirSource) Fetch(ctx context.Context) (map[string]string, string, error) {
+	files, err := readTemplates(os.DirFS(ds.path))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read templates from %s: %w", ds.path, err)
+	}
+	return files, checksum(files), nil
+}
+
+// cacheDirLocks serializes git operations per cache directory, since sources
+// sharing a directory would otherwise fetch and check out over each other
+var cacheDirLocks = struct {
+	sync.Mutex
+	dirs map[string]*sync.Mutex
+}{dirs: make(map[string]*sync.Mutex)}
+
+// cacheDirLock returns the lock of a cache directory
+func cacheDirLock(dir string) *sync.Mutex {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	cacheDirLocks.Lock()
+	defer cacheDirLocks.Unlock()
+	lock, ok := cacheDirLocks.dirs[dir]
+	if !ok {
+		lock = &sync.Mutex{}
+		cacheDirLocks.dirs[dir] = lock
+	}
+	return lock
+}
+
+// GitSource loads templates from a pinned ref of a Git repository
+type GitSource struct {
+	url      string
+	ref      string
+	path     string
+	cacheDir string
+}
+
+// NewGitSource creates a source that fetches ref from url into cacheDir and
+// reads templates from path inside the repository
+func NewGitSource(url, ref, path, cacheDir string) *GitSource {
+	return &GitSource{
+		url:      url,
+		ref:      ref,
+		path:     path,
+		cacheDir: cacheDir,
+	}
+}
+
+// Name returns the source description
+func (gs *GitSource) Name() string {
+	return fmt.Sprintf("git:%s@%s", redactURL(gs.url), gs.ref)
+}
+
+// Fetch fetches the configured ref and reads templates from the checkout.
+// The returned version is the commit SHA the ref resolved to. Fetches into the
+// same cache directory run one at a time.
+func (gs *GitSource) Fetch(ctx context.Context) (map[string]string, string, error) {
+	lock := cacheDirLock(gs.cacheDir)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := os.Stat(filepath.Join(gs.cacheDir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(gs.cacheDir, 0o755); err != nil {
+			return nil, "", fmt.Errorf("failed to create template cache dir: %w", err)
+		}
+		if _, err := gs.git(ctx, "init", "--quiet"); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if _, err := gs.git(ctx, "fetch", "--quiet", "--depth", "1", gs.url, gs.ref); err != nil {
+		return nil, "", err
+	}
+	if _, err := gs.git(ctx, "checkout", "--quiet", "--force", "FETCH_HEAD"); err != nil {
+		return nil, "", err
+	}
+
+	sha, err := gs.git(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, "", err
+	}
+
+	files, err := readTemplates(os.DirFS(filepath.Join(gs.cacheDir, gs.path)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read templates from %s: %w", gs.Name(), err)
+	}
+	return files, sha, nil
+}
+
+// git runs a git command inside the cache directory
+func (gs *GitSource) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", gs.cacheDir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(redactURL(stderr.String())))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// readTemplates reads all template files at the root of fsys keyed by name
+func readTemplates(fsys fs.FS) (map[string]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name, ok := templateName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		files[name] = string(body)
+	}
+
+	return files, nil
+}
+
+// templateName strips the template extension from a file name
+func templateName(fileName string) (string, bool) {
+	for _, ext := range templateExtensions {
+		if strings.HasSuffix(fileName, ext) {
+			return strings.TrimSuffix(fileName, ext), true
+		}
+	}
+	return "", false
+}
+
+// checksum returns a short content hash over a set of templates
+func checksum(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(files[name]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// redactURL hides credentials embedded in a repository URL
+func redactURL(s string) string {
+	start := strings.Index(s, "://")
+	if start == -1 {
+		return s
+	}
+	at := strings.Index(s[start+3:], "@")
+	slash := strings.Index(s[start+3:], "/")
+	if at == -1 || (slash != -1 && slash < at) {
+		return s
+	}
+	return s[:start+3] + "***" + s[start+3+at:]
+}
@@ -0,0 +1,63 @@
+package template_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/template"
+)
+
+// newTemplateRepo creates a Git repository with one template committed on main
+func newTemplateRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "templates", "ec2.yml.tmpl"), []byte("name: {{ .WorkflowName }}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "templates"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", args[0], err, out)
+		}
+	}
+	return dir
+}
+
+func TestGitSourceConcurrentFetches(t *testing.T) {
+	repo := newTemplateRepo(t)
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Go(func() {
+			files, _, err := template.NewGitSource("file://"+repo, "main", "templates", cacheDir).Fetch(context.Background())
+			if err == nil && files["ec2"] == "" {
+				t.Errorf("fetched templates %v, want ec2", files)
+			}
+			errs <- err
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Fetch: %v", err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
+)
+
+// PartialPrefix marks templates that only hold {{define}} blocks
+const PartialPrefix = "_"
+
+// Template represents a loaded workflow template
+type Template struct {
+	Name     string    `json:"name"`
+	Source   string    `json:"source"`
+	Version  string    `json:"version"`
+	LoadedAt time.Time `json:"loadedAt"`
+	Body     string    `json:"-"`
+}
+
+// Store holds the currently loaded workflow templates.
+// Built-in templates are always available and are overridden by templates
+// of the same name from the configured source. Templates whose name starts
+// with PartialPrefix are partials shared by every workflow template.
+type Store struct {
+	mu        sync.RWMutex
+	source    Source
+	templates map[string]Template
+	// active names the source of the templates in use, which stays "embedded"
+	// until the configured source loads
+	active    string
+	version   string
+	loadedAt  time.Time
+	lastError error
+}
+
+// NewStore creates a template store backed by source, preloaded with the
+// built-in templates
+func NewStore(source Source) *Store {
+	store := &Store{
+		source:    source,
+		templates: make(map[string]Template),
+	}
+
+	builtins, version, err := NewEmbeddedSource().Fetch(context.Background())
+	if err != nil {
+		panic(fmt.Sprintf("failed to load embedded templates: %v", err))
+	}
+	store.apply("embedded", version, builtins)
+
+	return store
+}
+
+// Load fetches templates from the source and swaps them in if all of them parse
+func (s *Store) Load(ctx context.Context) error {
+	files, version, err := s.source.Fetch(ctx)
+	if err == nil {
+		err = parseAll(files)
+	}
+
+	if err != nil {
+		s.mu.Lock()
+		s.lastError = err
+		s.mu.Unlock()
+		return err
+	}
+
+	builtins, builtinVersion, err := NewEmbeddedSource().Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.templates = make(map[string]Template)
+	s.apply("embedded", builtinVersion, builtins)
+	s.apply(s.source.Name(), version, files)
+	return nil
+}
+
+// apply records templates from one source; callers must hold the write lock
+// unless the store is not yet shared
+func (s *Store) apply(sourceName, version string, files map[string]string) {
+	now := time.Now()
+	for name, body := range files {
+		s.templates[name] = Template{
+			Name:     name,
+			Source:   sourceName,
+			Version:  version,
+			LoadedAt: now,
+			Body:     body,
+		}
+	}
+	s.active = sourceName
+	s.version = version
+	s.loadedAt = now
+	s.lastError = nil
+}
+
+// Watch reloads templates every interval until ctx is cancelled
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			previous := s.Version()
+			if err := s.Load(ctx); err != nil {
+				logger.Error().Err(err).Str("source", s.source.Name()).Msg("Failed to reload workflow templates")
+				continue
+			}
+			if current := s.Version(); current != previous {
+				logger.Info().
+					Str("source", s.source.Name()).
+					Str("previous_version", previous).
+					Str("version", current).
+					Msg("Workflow templates reloaded")
+			}
+		}
+	}
+}
+
+// Get returns the template with the given name
+func (s *Store) Get(name string) (Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tmpl, ok := s.templates[name]
+	if !ok {
+		return Template{}, fmt.Errorf("template '%s' not found", name)
+	}
+	return tmpl, nil
+}
+
+// List returns all loaded templates sorted by name
+func (s *Store) List() []Template {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]Template, 0, len(s.templates))
+	for _, tmpl := range s.templates {
+		templates = append(templates, tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// Partials returns the loaded partial templates sorted by name
+func (s *Store) Partials() []Template {
+	var partials []Template
+	for _, tmpl := range s.List() {
+		if strings.HasPrefix(tmpl.Name, PartialPrefix) {
+			partials = append(partials, tmpl)
+		}
+	}
+	return partials
+}
+
+// Status describes what the store has loaded. Source is the source of the
+// templates in use, which differs from ConfiguredSource while the configured
+// source has not loaded.
+type Status struct {
+	Source           string    `json:"source"`
+	ConfiguredSource string    `json:"configuredSource"`
+	Version          string    `json:"version"`
+	LoadedAt         time.Time `json:"loadedAt"`
+	LastError        string    `json:"lastError,omitempty"`
+}
+
+// Status returns the active and configured source, version and last reload
+// error of the store
+func (s *Store) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{
+		Source:           s.active,
+		ConfiguredSource: s.source.Name(),
+		Version:          s.version,
+		LoadedAt:         s.loadedAt,
+	}
+	if s.lastError != nil {
+		status.LastError = s.lastError.Error()
+	}
+	return status
+}
+
+// Version returns the version of the last successful load
+func (s *Store) Version() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// parseAll checks that every template parses with the generator func map
+func parseAll(files map[string]string) error {
+	for name, body := range files {
+		if _, err := template.New(name).Funcs(defaultFuncMap()).Parse(body); err != nil {
+			return fmt.Errorf("template '%s' is invalid: %w", name, err)
+		}
+	}
+	return nil
+}
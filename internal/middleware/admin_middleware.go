@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vmaurya-21/Calance-Workflow/internal/utils"
+)
+
+// IsAdmin reports whether the authenticated user is one of the admins, given
+// as GitHub logins. It must run after AuthMiddleware.
+func IsAdmin(c *gin.Context, admins []string) bool {
+	username := c.GetString("username")
+	if username == "" {
+		return false
+	}
+	for _, admin := range admins {
+		if strings.EqualFold(strings.TrimSpace(admin), username) {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminMiddleware only lets admins through; with no admins configured every
+// request is rejected. It must run after AuthMiddleware.
+func AdminMiddleware(admins []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAdmin(c, admins) {
+			utils.ForbiddenResponse(c, "Admin access required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
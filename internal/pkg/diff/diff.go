@@ -0,0 +1,227 @@
+// Package diff compares texts line by line and renders the result as a
+// unified diff.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextLines is the number of unchanged lines shown around each change
+const ContextLines = 3
+
+// Result is the line diff of two texts
+type Result struct {
+	// Unified is the diff in unified format, empty when the texts are equal
+	Unified   string
+	Additions int
+	Deletions int
+}
+
+// opKind is the kind of a line in an edit script
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op is one line of an edit script
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified compares two texts and renders the differences in unified format
+// under the given file names
+func Unified(oldName, newName, oldText, newText string) Result {
+	ops := editScript(splitLines(oldText), splitLines(newText))
+
+	var result Result
+	for _, o := range ops {
+		switch o.kind {
+		case opInsert:
+			result.Additions++
+		case opDelete:
+			result.Deletions++
+		}
+	}
+	if result.Additions == 0 && result.Deletions == 0 {
+		return result
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		writeHunk(&b, ops, h)
+	}
+	result.Unified = b.String()
+	return result
+}
+
+// splitLines splits text into lines that keep their line terminator, so a
+// missing newline at the end of the text counts as a difference
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript returns a shortest edit script turning a into b, using Myers'
+// O(ND) algorithm
+func editScript(a, b []string) []op {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	// trace[d] holds the furthest reaching x of every diagonal before step d
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the trace from the end of both texts to their start
+func backtrack(a, b []string, trace [][]int) []op {
+	x, y := len(a), len(b)
+	var ops []op
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		// v covers diagonals -d-1 to d+1
+		at := func(k int) int { return v[k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{kind: opEqual, line: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, op{kind: opInsert, line: b[y]})
+			} else {
+				x--
+				ops = append(ops, op{kind: opDelete, line: a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// hunk is a range of the edit script rendered together
+type hunk struct {
+	start, end int
+}
+
+// hunks groups changes whose context lines touch or overlap
+func hunks(ops []op) []hunk {
+	var result []hunk
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+
+		start := i - ContextLines
+		if start < 0 {
+			start = 0
+		}
+		if n := len(result); n > 0 && start <= result[n-1].end {
+			start = result[n-1].start
+			result = result[:n-1]
+		}
+
+		end := i + 1
+		for end < len(ops) && ops[end].kind != opEqual {
+			end++
+		}
+		i = end - 1
+		end += ContextLines
+		if end > len(ops) {
+			end = len(ops)
+		}
+		result = append(result, hunk{start: start, end: end})
+	}
+	return result
+}
+
+// writeHunk renders one hunk with its range header
+func writeHunk(b *strings.Builder, ops []op, h hunk) {
+	oldStart, newStart := 0, 0
+	for _, o := range ops[:h.start] {
+		if o.kind != opInsert {
+			oldStart++
+		}
+		if o.kind != opDelete {
+			newStart++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, o := range ops[h.start:h.end] {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops[h.start:h.end] {
+		b.WriteByte(byte(o.kind))
+		b.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk range; an empty range names the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
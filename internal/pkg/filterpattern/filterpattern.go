@@ -0,0 +1,127 @@
+// Package filterpattern implements the glob syntax GitHub Actions uses for
+// branch, tag and path filters.
+package filterpattern
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidPattern is returned for patterns GitHub would reject
+var ErrInvalidPattern = errors.New("invalid filter pattern")
+
+// characterClass accepts the alphanumeric characters and ranges GitHub allows in []
+var characterClass = regexp.MustCompile(`^(?:[A-Za-z0-9](?:-[A-Za-z0-9])?|[._/-])+$`)
+
+// Pattern is a parsed filter pattern
+type Pattern struct {
+	// Source is the pattern as written, including a leading '!'
+	Source string
+	// Negated is true for patterns starting with '!', which exclude matches
+	Negated bool
+
+	expr *regexp.Regexp
+}
+
+// Parse validates a filter pattern and translates it to a regular expression.
+// '*' matches any characters except '/', '**' matches any characters, '?' and
+// '+' make the preceding character optional or repeatable, '[]' matches one
+// character or range, a leading '!' negates the pattern and '\' escapes the
+// next character.
+func Parse(pattern string) (*Pattern, error) {
+	p := &Pattern{Source: pattern}
+
+	body := pattern
+	if strings.HasPrefix(body, "!") {
+		p.Negated = true
+		body = body[1:]
+	}
+	if body == "" {
+		return nil, fmt.Errorf("%w: '%s' is empty", ErrInvalidPattern, pattern)
+	}
+
+	expr, err := translate(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s' %v", ErrInvalidPattern, pattern, err)
+	}
+	p.expr, err = regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s' %v", ErrInvalidPattern, pattern, err)
+	}
+	return p, nil
+}
+
+// Validate reports whether pattern is a valid filter pattern
+func Validate(pattern string) error {
+	_, err := Parse(pattern)
+	return err
+}
+
+// Regexp returns the anchored POSIX extended regular expression equivalent to
+// the pattern, ignoring negation
+func (p *Pattern) Regexp() string {
+	return p.expr.String()
+}
+
+// Match reports whether s matches the pattern, ignoring negation
+func (p *Pattern) Match(s string) bool {
+	return p.expr.MatchString(s)
+}
+
+func translate(body string) (string, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	// quantifiable tracks whether the previous token can take '?' or '+'
+	quantifiable := false
+	runes := []rune(body)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '\\':
+			if i+1 == len(runes) {
+				return "", errors.New("ends with an escape character")
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+			quantifiable = true
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				b.WriteString(".*")
+			} else {
+				b.WriteString("[^/]*")
+			}
+			quantifiable = false
+		case '?', '+':
+			if !quantifiable {
+				return "", fmt.Errorf("has '%c' without a preceding character", r)
+			}
+			b.WriteRune(r)
+			quantifiable = false
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return "", errors.New("has an unterminated '['")
+			}
+			class := string(runes[i+1 : end])
+			if class == "" || !characterClass.MatchString(class) {
+				return "", fmt.Errorf("has an invalid character class '[%s]'", class)
+			}
+			b.WriteString("[" + class + "]")
+			i = end
+			quantifiable = true
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			quantifiable = true
+		}
+	}
+
+	b.WriteString("$")
+	return b.String(), nil
+}
@@ -7,17 +7,49 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vmaurya-21/Calance-Workflow/internal/pkg/logger"
 )
 
 // Client wraps http.Client with common functionality
 type Client struct {
 	httpClient *http.Client
+	options    Options
+}
+
+var (
+	defaultMu        sync.Mutex
+	defaultOptions   = DefaultOptions()
+	defaultTransport = newTransport(defaultOptions)
+)
+
+// SetDefaultOptions sets the options of clients created afterwards by
+// NewClient, which share one transport and its connection pool
+func SetDefaultOptions(opts Options) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultOptions = opts
+	defaultTransport = newTransport(opts)
 }
 
-// NewClient creates a new HTTP client wrapper
+// NewClient creates a new HTTP client wrapper with the default options
 func NewClient() *Client {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
 	return &Client{
-		httpClient: &http.Client{},
+		httpClient: &http.Client{Transport: defaultTransport},
+		options:    defaultOptions,
+	}
+}
+
+// NewClientWithOptions creates a new HTTP client wrapper with its own transport
+func NewClientWithOptions(opts Options) *Client {
+	return &Client{
+		httpClient: &http.Client{Transport: newTransport(opts)},
+		options:    opts,
 	}
 }
 
@@ -27,6 +59,11 @@ type Request struct {
 	URL     string
 	Headers map[string]string
 	Body    interface{}
+	// Timeout limits each attempt instead of the client's timeout
+	Timeout time.Duration
+	// Retry allows retrying a request whose method is not GET, HEAD or
+	// OPTIONS, for callers that know it is safe to send again
+	Retry bool
 }
 
 // Response represents an HTTP response
@@ -36,15 +73,83 @@ type Response struct {
 	Headers    http.Header
 }
 
-// Do performs an HTTP request
+// Do performs an HTTP request. GET, HEAD and OPTIONS requests, and requests
+// with Retry set, are retried after connection failures and 5xx responses as
+// the client's retry policy allows; the last response or error is returned.
 func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
-	var bodyReader io.Reader
+	var bodyBytes []byte
 	if req.Body != nil {
-		bodyBytes, err := json.Marshal(req.Body)
+		var err error
+		bodyBytes, err = json.Marshal(req.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewBuffer(bodyBytes)
+	}
+
+	timeout := c.options.Timeout
+	if req.Timeout > 0 {
+		timeout = req.Timeout
+	}
+
+	policy := c.options.Retry
+	maxAttempts := policy.MaxAttempts
+	if !(isSafeMethod(req.Method) || req.Retry) || maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req, bodyBytes, timeout)
+
+		var retryable bool
+		if err != nil {
+			retryable = isRetryableError(ctx, err)
+		} else {
+			retryable = isRetryableStatus(resp.StatusCode)
+		}
+		if !retryable || attempt >= maxAttempts {
+			return resp, err
+		}
+
+		delay := policy.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := resp.RetryAfter(); ok && retryAfter > delay {
+				delay = retryAfter
+			}
+		}
+		if policy.MaxElapsed > 0 && time.Since(start)+delay > policy.MaxElapsed {
+			return resp, err
+		}
+
+		event := logger.Warn().
+			Str("method", req.Method).
+			Str("url", req.URL).
+			Int("attempt", attempt).
+			Dur("backoff", delay)
+		if err != nil {
+			event = event.Err(err)
+		} else {
+			event = event.Int("status", resp.StatusCode)
+		}
+		event.Msg("Retrying HTTP request")
+
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return resp, err
+		}
+	}
+}
+
+// attempt sends a request once and reads its response within timeout
+func (c *Client) attempt(ctx context.Context, req Request, bodyBytes []byte, timeout time.Duration) (*Response, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var bodyReader io.Reader
+	if bodyBytes != nil {
+		bodyReader = bytes.NewReader(bodyBytes)
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, bodyReader)
@@ -57,7 +162,7 @@ func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
 		httpReq.Header.Set(key, value)
 	}
 
-	if req.Body != nil {
+	if bodyBytes != nil {
 		httpReq.Header.Set("Content-Type", "application/json")
 	}
 
@@ -92,6 +197,22 @@ func (r *Response) IsSuccess() bool {
 	return r.StatusCode >= 200 && r.StatusCode < 300
 }
 
+// RetryAfter returns the delay asked for by the Retry-After header, given in
+// seconds or as an HTTP date
+func (r *Response) RetryAfter() (time.Duration, bool) {
+	value := r.Headers.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
 // GetErrorMessage returns a formatted error message from the response
 func (r *Response) GetErrorMessage() string {
 	return fmt.Sprintf("status %d: %s", r.StatusCode, string(r.Body))
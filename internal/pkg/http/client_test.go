@@ -0,0 +1,276 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testOptions retries quickly so that tests do not wait on backoff
+func testOptions() Options {
+	opts := DefaultOptions()
+	opts.Timeout = 2 * time.Second
+	opts.Retry = RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		MaxElapsed:     5 * time.Second,
+	}
+	return opts
+}
+
+// failingServer answers each attempt with the handler for its number,
+// starting at 1, and counts the attempts
+func failingServer(t *testing.T, handler func(attempt int32, w http.ResponseWriter, r *http.Request)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(attempts.Add(1), w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+// failFirst answers the first attempt with status and later ones with 200
+func failFirst(status int) func(int32, http.ResponseWriter, *http.Request) {
+	return func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		if attempt == 1 {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func TestDoRetriesServerErrors(t *testing.T) {
+	server, attempts := failingServer(t, failFirst(http.StatusServiceUnavailable))
+
+	resp, err := NewClientWithOptions(testOptions()).Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+}
+
+func TestDoDoesNotRetryWrites(t *testing.T) {
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		t.Run(method, func(t *testing.T) {
+			server, attempts := failingServer(t, failFirst(http.StatusServiceUnavailable))
+
+			resp, err := NewClientWithOptions(testOptions()).Do(context.Background(), Request{
+				Method: method,
+				URL:    server.URL,
+				Body:   map[string]string{"key": "value"},
+			})
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			if resp.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+			}
+			if got := attempts.Load(); got != 1 {
+				t.Errorf("attempts = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestDoRetriesWritesThatOptIn(t *testing.T) {
+	server, attempts := failingServer(t, failFirst(http.StatusServiceUnavailable))
+
+	resp, err := NewClientWithOptions(testOptions()).Do(context.Background(), Request{
+		Method: http.MethodPut,
+		URL:    server.URL,
+		Body:   map[string]string{"key": "value"},
+		Retry:  true,
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+}
+
+func TestDoDoesNotRetryNotImplemented(t *testing.T) {
+	server, attempts := failingServer(t, failFirst(http.StatusNotImplemented))
+
+	resp, err := NewClientWithOptions(testOptions()).Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotImplemented)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestDoRetriesDroppedConnections(t *testing.T) {
+	server, attempts := failingServer(t, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		if attempt == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack: %v", err)
+				return
+			}
+			// Close without a response, resetting the connection
+			if tcp, ok := conn.(interface{ SetLinger(int) error }); ok {
+				_ = tcp.SetLinger(0)
+			}
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := NewClientWithOptions(testOptions()).Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+}
+
+func TestDoHonoursRetryAfter(t *testing.T) {
+	server, attempts := failingServer(t, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		if attempt == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	start := time.Now()
+	resp, err := NewClientWithOptions(testOptions()).Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestDoStopsAtMaxElapsed(t *testing.T) {
+	server, attempts := failingServer(t, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	opts := testOptions()
+	opts.Retry.MaxElapsed = 500 * time.Millisecond
+
+	start := time.Now()
+	resp, err := NewClientWithOptions(opts).Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed >= opts.Retry.MaxElapsed {
+		t.Errorf("returned after %v, want less than MaxElapsed %v", elapsed, opts.Retry.MaxElapsed)
+	}
+}
+
+func TestDoTimesOutEachAttempt(t *testing.T) {
+	server, attempts := failingServer(t, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		if attempt == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	opts := testOptions()
+	opts.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	resp, err := NewClientWithOptions(opts).Do(context.Background(), Request{Method: http.MethodGet, URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("returned after %v, want the first attempt cut off at %v", elapsed, opts.Timeout)
+	}
+}
+
+func TestDoRequestTimeoutOverridesClientTimeout(t *testing.T) {
+	server, attempts := failingServer(t, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	opts := testOptions()
+	opts.Timeout = 50 * time.Millisecond
+
+	resp, err := NewClientWithOptions(opts).Do(context.Background(), Request{
+		Method:  http.MethodGet,
+		URL:     server.URL,
+		Timeout: 2 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
+
+func TestDoDoesNotRetryTimedOutWrites(t *testing.T) {
+	server, attempts := failingServer(t, func(attempt int32, w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	opts := testOptions()
+	opts.Timeout = 50 * time.Millisecond
+
+	_, err := NewClientWithOptions(opts).Do(context.Background(), Request{Method: http.MethodPost, URL: server.URL})
+	if err == nil {
+		t.Fatal("Do succeeded, want a timeout")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page size limits of list endpoints
+const (
+	DefaultPerPage = 30
+	MaxPerPage     = 100
+)
+
+// PageParams are the page, per_page and cursor query parameters of a list endpoint
+type PageParams struct {
+	Page    int
+	PerPage int
+	Cursor  string
+	// Set reports whether any of the parameters was given
+	Set bool
+}
+
+// Pagination describes the page returned by a list endpoint
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	// Total is the number of items of all pages, when known
+	Total *int `json:"total,omitempty"`
+}
+
+// ParsePageParams reads the page, per_page and cursor query parameters. page
+// defaults to 1 and per_page to DefaultPerPage; a cursor cannot be combined with page.
+func ParsePageParams(c *gin.Context) (PageParams, error) {
+	params := PageParams{Page: 1, PerPage: DefaultPerPage, Cursor: c.Query("cursor")}
+
+	if value := c.Query("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return params, errors.New("page must be a positive number")
+		}
+		if params.Cursor != "" {
+			return params, errors.New("page cannot be combined with cursor")
+		}
+		params.Page = page
+		params.Set = true
+	}
+
+	if value := c.Query("per_page"); value != "" {
+		perPage, err := strconv.Atoi(value)
+		if err != nil || perPage < 1 || perPage > MaxPerPage {
+			return params, errors.New("per_page must be between 1 and " + strconv.Itoa(MaxPerPage))
+		}
+		params.PerPage = perPage
+		params.Set = true
+	}
+
+	if params.Cursor != "" {
+		params.Set = true
+	}
+	return params, nil
+}
+
+// Pagination describes the page fetched with these parameters. Page and page
+// size are left out for cursors, which carry their own.
+func (p PageParams) Pagination(nextCursor string, total *int) Pagination {
+	pagination := Pagination{NextCursor: nextCursor, HasMore: nextCursor != "", Total: total}
+	if p.Cursor == "" {
+		pagination.Page = p.Page
+		pagination.PerPage = p.PerPage
+	}
+	return pagination
+}
+
+// PaginatedResponse sends a successful JSON response for a page of a list endpoint
+func PaginatedResponse(c *gin.Context, statusCode int, message string, data interface{}, pagination Pagination) {
+	c.JSON(statusCode, gin.H{
+		"success":    true,
+		"message":    message,
+		"data":       data,
+		"pagination": pagination,
+	})
+}
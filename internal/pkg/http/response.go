@@ -1,7 +1,11 @@
 package http
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -49,7 +53,41 @@ func NotFoundResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusNotFound, message, nil)
 }
 
-// InternalServerErrorResponse sends a 500 Internal Server Error response
+// RetryableError is an error of an upstream API that asks to retry later,
+// such as an exhausted rate limit
+type RetryableError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// TooManyRequestsResponse sends a 429 Too Many Requests response with a Retry-After header
+func TooManyRequestsResponse(c *gin.Context, message string, retryAfter time.Duration, err error) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	ErrorResponse(c, http.StatusTooManyRequests, message, err)
+}
+
+// InternalServerErrorResponse sends a 500 Internal Server Error response, or a
+// 429 Too Many Requests response when err asks to retry later
 func InternalServerErrorResponse(c *gin.Context, message string, err error) {
+	var retryable RetryableError
+	if errors.As(err, &retryable) {
+		TooManyRequestsResponse(c, message, retryable.RetryAfter(), err)
+		return
+	}
 	ErrorResponse(c, http.StatusInternalServerError, message, err)
 }
+
+// ValidationErrorResponse sends a 422 Unprocessable Entity response with validation details
+func ValidationErrorResponse(c *gin.Context, message string, err error, details interface{}) {
+	response := gin.H{
+		"success": false,
+		"message": message,
+		"data":    details,
+	}
+
+	if err != nil {
+		response["error"] = err.Error()
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, response)
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. GET, HEAD and OPTIONS
+// requests, and requests that opt in with Request.Retry, are retried after
+// connection failures and 5xx responses.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first; 1 or less disables retries
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled for each further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff time.Duration
+	// MaxElapsed stops retrying once the next attempt would start later than this
+	// after the first; zero means no limit
+	MaxElapsed time.Duration
+}
+
+// Options configures a Client
+type Options struct {
+	// Timeout limits each attempt of a request, unless the request sets its own; zero means no limit
+	Timeout time.Duration
+	Retry   RetryPolicy
+
+	// Connection pooling of the transport
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits connections per host, including active ones; zero means no limit
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+}
+
+// DefaultOptions returns the options of clients created by NewClient unless
+// SetDefaultOptions changed them
+func DefaultOptions() Options {
+	return Options{
+		Timeout: 30 * time.Second,
+		Retry: RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 200 * time.Millisecond,
+			MaxBackoff:     5 * time.Second,
+			MaxElapsed:     30 * time.Second,
+		},
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// newTransport returns a transport with the pooling settings of opts
+func newTransport(opts Options) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	return transport
+}
+
+// isSafeMethod reports whether a request with method only reads, so that
+// sending it again cannot change anything. PUT and DELETE are idempotent too,
+// but a retry after a lost response may still repeat a write GitHub already
+// applied, so they are only retried when the request opts in.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus reports whether a response status is a server failure
+// worth retrying. 501 Not Implemented will not change on retry.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode >= 500 && statusCode != http.StatusNotImplemented
+}
+
+// isRetryableError reports whether a failed attempt may succeed when retried:
+// connections reset or closed by the server, and attempts that timed out
+// while the request's own context is still alive
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns the delay before retry number retry (starting at 1): the
+// exponential backoff with half of it randomized, so that clients failing
+// together do not retry together
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// sleep waits for delay or until ctx is done
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
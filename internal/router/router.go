@@ -1,12 +1,16 @@
 package router
 
 import (
+	"context"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/vmaurya-21/Calance-Workflow/internal/config"
 	"gorm.io/gorm"
 
 	// New modular handlers
 	authHandler "github.com/vmaurya-21/Calance-Workflow/internal/api/handlers/auth"
+	githubHandler "github.com/vmaurya-21/Calance-Workflow/internal/api/handlers/github"
 	orgHandler "github.com/vmaurya-21/Calance-Workflow/internal/api/handlers/organization"
 	repoHandler "github.com/vmaurya-21/Calance-Workflow/internal/api/handlers/repository"
 	workflowHandler "github.com/vmaurya-21/Calance-Workflow/internal/api/handlers/workflow"
@@ -19,6 +23,9 @@ import (
 
 	// Infrastructure
 	database "github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/database/repositories"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/github"
+	"github.com/vmaurya-21/Calance-Workflow/internal/infrastructure/template"
+	pkghttp "github.com/vmaurya-21/Calance-Workflow/internal/pkg/http"
 
 	// Utilities
 	"github.com/vmaurya-21/Calance-Workflow/internal/logger"
@@ -42,11 +49,19 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	// Initialize repositories
 	userRepo := database.NewUserRepository(db)
 	tokenRepo := database.NewTokenRepository(db)
+	historyRepo := database.NewHistoryRepository(db)
+	pullRequestRepo := database.NewPullRequestRepository(db)
+
+	setupHTTPClient(cfg)
+	setupRateLimitPolicy(cfg)
+	setupResponseCache(cfg, db)
 
 	// Initialize domain services
 	scopes := []string{"user:email", "read:user", "read:org", "repo", "workflow", "read:packages"}
 	authService := authDomain.NewService(cfg.GitHub.ClientID, cfg.GitHub.ClientSecret, cfg.GitHub.RedirectURL, scopes)
-	workflowService := workflowDomain.NewService()
+	templateStore := setupTemplateStore(cfg)
+	workflowService := workflowDomain.NewService(templateStore, setupDirectCommitPolicy(cfg))
+	setupBranchJanitor(cfg, workflowService, historyRepo, pullRequestRepo, tokenRepo)
 	repositoryService := repoDomain.NewService()
 	organizationService := orgDomain.NewService()
 
@@ -54,7 +69,8 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	authHandlers := authHandler.NewHandler(authService, userRepo, tokenRepo)
 	organizationHandlers := orgHandler.NewHandler(organizationService, tokenRepo)
 	repositoryHandlers := repoHandler.NewHandler(repositoryService, tokenRepo)
-	workflowHandlers := workflowHandler.NewHandler(workflowService, tokenRepo)
+	workflowHandlers := workflowHandler.NewHandler(workflowService, tokenRepo, historyRepo, pullRequestRepo, cfg.GitHub.WebhookSecret, cfg.Admin.Users)
+	githubHandlers := githubHandler.NewHandler(tokenRepo)
 
 	// Health check route
 	r.GET("/ping", func(c *gin.Context) {
@@ -67,6 +83,9 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	// API routes
 	api := r.Group("/api")
 	{
+		// GitHub webhooks (public, verified by signature)
+		api.POST("/webhooks/github", workflowHandlers.GitHubWebhook)
+
 		// Auth routes
 		auth := api.Group("/auth")
 		{
@@ -94,6 +113,7 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 		{
 			organizations.GET("", organizationHandlers.List)
 			organizations.GET("/:org/repositories", organizationHandlers.GetRepositories)
+			organizations.GET("/:org/workflows/drift", workflowHandlers.DetectDrift)
 		}
 
 		// Repository routes (protected)
@@ -122,10 +142,30 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 			packages.GET("/org/:org", repositoryHandlers.GetOrgPackages)
 		}
 
+		// GitHub API usage routes (protected)
+		githubAPI := api.Group("/github")
+		githubAPI.Use(middleware.AuthMiddleware())
+		{
+			githubAPI.GET("/rate-limit", githubHandlers.GetRateLimit)
+		}
+
+		// Workflow template routes (protected)
+		templates := api.Group("/templates")
+		templates.Use(middleware.AuthMiddleware())
+		{
+			templates.GET("", workflowHandlers.ListTemplates)
+			templates.POST("/reload", middleware.AdminMiddleware(cfg.Admin.Users), workflowHandlers.ReloadTemplates)
+		}
+
 		// Workflow routes (protected)
 		workflows := api.Group("/workflows")
 		workflows.Use(middleware.AuthMiddleware())
 		{
+			workflows.GET("/deployment-types", workflowHandlers.ListDeploymentTypes)
+			workflows.GET("/history", workflowHandlers.ListHistory)
+			workflows.GET("/pull-requests", workflowHandlers.ListPullRequests)
+			workflows.POST("/upgrades", workflowHandlers.StartUpgrade)
+			workflows.GET("/upgrades/:id", workflowHandlers.GetUpgrade)
 			workflows.GET("/:owner/:repo", workflowHandlers.List)
 			workflows.POST("/create", workflowHandlers.Create)
 			workflows.POST("/preview", workflowHandlers.Preview)
@@ -133,8 +173,185 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 			// Workflow edit endpoints
 			workflows.GET("/:owner/:repo/file", workflowHandlers.GetWorkflowContent)
 			workflows.PUT("/:owner/:repo/file", workflowHandlers.UpdateWorkflow)
+			workflows.POST("/:owner/:repo/diff", workflowHandlers.Diff)
+			workflows.GET("/:owner/:repo/config", workflowHandlers.GetConfig)
+			workflows.POST("/:owner/:repo/regenerate", workflowHandlers.Regenerate)
 		}
 	}
 
 	return r
 }
+
+// setupTemplateStore loads workflow templates from the configured source and
+// starts hot reload for directory and Git sources. The built-in templates stay
+// in use if loading fails.
+func setupTemplateStore(cfg *config.Config) *template.Store {
+	var source template.Source
+	switch {
+	case cfg.Templates.GitURL != "":
+		source = template.NewGitSource(cfg.Templates.GitURL, cfg.Templates.GitRef, cfg.Templates.GitPath, cfg.Templates.CacheDir)
+	case cfg.Templates.Dir != "":
+		source = template.NewDirSource(cfg.Templates.Dir)
+	default:
+		source = template.NewEmbeddedSource()
+	}
+
+	store := template.NewStore(source)
+	if err := store.Load(context.Background()); err != nil {
+		logger.Error().Err(err).Str("source", source.Name()).Msg("Failed to load workflow templates, using built-in templates")
+	} else {
+		logger.Info().Str("source", source.Name()).Str("version", store.Version()).Msg("Workflow templates loaded")
+	}
+
+	// The built-in templates cannot change, so there is nothing to watch
+	_, embedded := source.(*template.EmbeddedSource)
+	if cfg.Templates.ReloadIntervalSeconds > 0 && !embedded {
+		go store.Watch(context.Background(), time.Duration(cfg.Templates.ReloadIntervalSeconds)*time.Second)
+	}
+
+	return store
+}
+
+// setupDirectCommitPolicy builds the policy for workflow changes without a pull
+// request. An invalid policy is logged and allows no direct commits.
+func setupDirectCommitPolicy(cfg *config.Config) *workflowDomain.DirectCommitPolicy {
+	policy, err := workflowDomain.NewDirectCommitPolicy(cfg.Policy.DirectCommitRepositories)
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid DIRECT_COMMIT_REPOSITORIES, direct commits are disabled")
+		return nil
+	}
+	return policy
+}
+
+// setupHTTPClient applies the configured timeouts, retries and connection
+// pooling to the HTTP clients created by the services
+func setupHTTPClient(cfg *config.Config) {
+	pkghttp.SetDefaultOptions(pkghttp.Options{
+		Timeout: time.Duration(cfg.HTTP.TimeoutSeconds) * time.Second,
+		Retry: pkghttp.RetryPolicy{
+			MaxAttempts:    cfg.HTTP.RetryMaxAttempts,
+			InitialBackoff: time.Duration(cfg.HTTP.RetryInitialBackoffMillis) * time.Millisecond,
+			MaxBackoff:     time.Duration(cfg.HTTP.RetryMaxBackoffMillis) * time.Millisecond,
+			MaxElapsed:     time.Duration(cfg.HTTP.RetryMaxElapsedSeconds) * time.Second,
+		},
+		MaxIdleConns:        cfg.HTTP.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.HTTP.MaxConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.HTTP.IdleConnTimeoutSeconds) * time.Second,
+	})
+}
+
+// setupRateLimitPolicy applies the configured handling of exhausted GitHub rate
+// limits to all GitHub clients. An unknown policy is logged and the default is kept.
+func setupRateLimitPolicy(cfg *config.Config) {
+	maxWait := time.Duration(cfg.GitHub.RateLimitMaxWaitSeconds) * time.Second
+	switch cfg.GitHub.RateLimitPolicy {
+	case "wait":
+		github.SetRateLimitPolicy(github.RateLimitPolicy{Wait: true, MaxWait: maxWait})
+	case "fail":
+		github.SetRateLimitPolicy(github.RateLimitPolicy{Wait: false})
+	default:
+		logger.Error().Str("policy", cfg.GitHub.RateLimitPolicy).
+			Msg("Invalid GITHUB_RATE_LIMIT_POLICY, expected 'wait' or 'fail'; using the default policy")
+	}
+}
+
+// setupResponseCache sets the cache used to revalidate GitHub GET responses.
+// Postgres entries not refreshed within the max age are removed hourly.
+func setupResponseCache(cfg *config.Config, db *gorm.DB) {
+	switch cfg.GitHub.ResponseCache {
+	case "memory":
+		github.SetResponseCache(github.NewMemoryResponseCache(cfg.GitHub.ResponseCacheMemoryMB << 20))
+	case "postgres":
+		cacheRepo := database.NewResponseCacheRepository(db)
+		github.SetResponseCache(cacheRepo)
+		if cfg.GitHub.ResponseCacheMaxAgeHours > 0 {
+			go pruneResponseCache(cacheRepo, time.Duration(cfg.GitHub.ResponseCacheMaxAgeHours)*time.Hour)
+		}
+	case "none":
+		return
+	default:
+		logger.Error().Str("cache", cfg.GitHub.ResponseCache).
+			Msg("Invalid GITHUB_RESPONSE_CACHE, expected 'memory', 'postgres' or 'none'; caching is disabled")
+		return
+	}
+
+	logger.Info().Str("cache", cfg.GitHub.ResponseCache).Msg("GitHub response cache enabled")
+}
+
+// pruneResponseCache removes cached GitHub responses older than maxAge every hour
+func pruneResponseCache(cacheRepo *database.ResponseCacheRepository, maxAge time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := cacheRepo.DeleteOlderThan(time.Now().Add(-maxAge))
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to prune GitHub response cache")
+			continue
+		}
+		if deleted > 0 {
+			logger.Info().Int64("deleted", deleted).Msg("Pruned GitHub response cache")
+		}
+	}
+}
+
+// setupBranchJanitor starts the periodic cleanup of stale workflow branches in
+// the repositories with workflow history, using the token of the user who
+// last changed a workflow in each
+func setupBranchJanitor(cfg *config.Config, service *workflowDomain.Service, historyRepo *database.HistoryRepository, pullRequestRepo *database.PullRequestRepository, tokenRepo *database.TokenRepository) {
+	if cfg.Janitor.IntervalMinutes <= 0 {
+		return
+	}
+
+	targets := func(ctx context.Context) ([]workflowDomain.JanitorTarget, error) {
+		histories, err := historyRepo.ListRepositories()
+		if err != nil {
+			return nil, err
+		}
+
+		var targets []workflowDomain.JanitorTarget
+		for _, history := range histories {
+			token, err := tokenRepo.FindByUserID(history.UserID)
+			if err != nil {
+				logger.Warn().Err(err).Str("owner", history.Owner).Str("repo", history.Repository).Str("user_id", history.UserID.String()).Msg("Failed to load token for branch janitor, skipping repository")
+				continue
+			}
+			if token == nil {
+				continue
+			}
+
+			names, err := historyRepo.ListWorkflowNames(history.Owner, history.Repository)
+			if err != nil {
+				logger.Warn().Err(err).Str("owner", history.Owner).Str("repo", history.Repository).Str("user_id", history.UserID.String()).Msg("Failed to list recorded workflows for branch janitor, skipping repository")
+				continue
+			}
+			branches, err := pullRequestRepo.ListHeadBranches(history.Owner, history.Repository)
+			if err != nil {
+				logger.Warn().Err(err).Str("owner", history.Owner).Str("repo", history.Repository).Str("user_id", history.UserID.String()).Msg("Failed to list tracked pull requests for branch janitor, skipping repository")
+				continue
+			}
+			targets = append(targets, workflowDomain.JanitorTarget{
+				Owner:         history.Owner,
+				Repository:    history.Repository,
+				Token:         token.AccessToken,
+				WorkflowNames: names,
+				Branches:      branches,
+			})
+		}
+		return targets, nil
+	}
+
+	maxAge := time.Duration(cfg.Janitor.MaxAgeHours) * time.Hour
+	janitor, err := workflowDomain.NewBranchJanitor(service, targets, maxAge)
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid BRANCH_JANITOR_MAX_AGE_HOURS, branch janitor is disabled")
+		return
+	}
+	go janitor.Run(context.Background(), time.Duration(cfg.Janitor.IntervalMinutes)*time.Minute)
+
+	logger.Info().
+		Int("interval_minutes", cfg.Janitor.IntervalMinutes).
+		Int("max_age_hours", cfg.Janitor.MaxAgeHours).
+		Msg("Branch janitor started")
+}
@@ -55,6 +55,15 @@ func UnauthorizedResponse(c *gin.Context, message string) {
 	})
 }
 
+// ForbiddenResponse sends a forbidden error response
+func ForbiddenResponse(c *gin.Context, message string) {
+	c.JSON(http.StatusForbidden, Response{
+		Success: false,
+		Message: message,
+		Error:   "Forbidden",
+	})
+}
+
 // NotFoundResponse sends a not found error response
 func NotFoundResponse(c *gin.Context, message string) {
 	c.JSON(http.StatusNotFound, Response{